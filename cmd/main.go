@@ -1,15 +1,25 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
 	"github.com/mroczekDNF/swift-api/internal/routes"
 	"github.com/mroczekDNF/swift-api/internal/services"
 )
 
+const swiftCodesFile = "data/swift_codes.csv"
+
+// idempotencyPurgeInterval is how often expired idempotency keys are removed
+const idempotencyPurgeInterval = time.Hour
+
 func getEnv(keys ...string) map[string]string {
 	values := make(map[string]string)
 	for _, key := range keys {
@@ -22,7 +32,126 @@ func getEnv(keys ...string) map[string]string {
 	return values
 }
 
+// loadColumnMapping returns the default column mapping extended by the JSON file columnsFile, if set.
+func loadColumnMapping(columnsFile string) (services.ColumnMapping, error) {
+	if columnsFile == "" {
+		return services.DefaultColumnMapping, nil
+	}
+	return services.LoadColumnMapping(columnsFile)
+}
+
+// logParseReport logs a summary of the parse report and, when rejectsFile is set,
+// writes the rejected rows there.
+func logParseReport(report services.ParseReport, rejectsFile string) error {
+	log.Printf("Parsed %s: %s", swiftCodesFile, report.Summary())
+
+	if rejectsFile != "" {
+		file, err := os.Create(rejectsFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := report.WriteRejects(file); err != nil {
+			return err
+		}
+		log.Printf("Rejected records written to %s", rejectsFile)
+	}
+	return nil
+}
+
+// decodeSwiftCodesFile transcodes the directory file to UTF-8 from encoding, which may be
+// services.EncodingAuto to detect it.
+func decodeSwiftCodesFile(file *os.File, encoding services.Encoding) (io.Reader, error) {
+	input, detected, err := services.DecodeInput(file, encoding)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Reading %s as %s", swiftCodesFile, detected)
+	return input, nil
+}
+
+// parseSwiftCodesFile parses the directory file and reports the outcome, see logParseReport.
+// columnsFile optionally names a JSON file with extra header aliases for vendor layouts.
+func parseSwiftCodesFile(columnsFile, rejectsFile string, encoding services.Encoding, policy services.DuplicatePolicy) ([]models.SwiftCode, error) {
+	mapping, err := loadColumnMapping(columnsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(swiftCodesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	input, err := decodeSwiftCodesFile(file, encoding)
+	if err != nil {
+		return nil, err
+	}
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(input, mapping, policy)
+	if err != nil {
+		return nil, err
+	}
+	return swiftCodes, logParseReport(report, rejectsFile)
+}
+
+// loadSwiftCodesFile streams the directory file into the empty table and reports the outcome.
+func loadSwiftCodesFile(columnsFile, rejectsFile string, encoding services.Encoding, policy services.DuplicatePolicy) error {
+	mapping, err := loadColumnMapping(columnsFile)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(swiftCodesFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	input, err := decodeSwiftCodesFile(file, encoding)
+	if err != nil {
+		return err
+	}
+	report, err := services.LoadSwiftCodesFromReader(db.DB, input, mapping, policy, services.StreamOptions{})
+	if err != nil {
+		return err
+	}
+	return logParseReport(report, rejectsFile)
+}
+
+// purgeIdempotencyKeys periodically removes idempotency keys older than window
+func purgeIdempotencyKeys(repo *repositories.IdempotencyKeyRepository, window time.Duration) {
+	for range time.Tick(idempotencyPurgeInterval) {
+		if removed, err := repo.DeleteExpiredKeys(window); err != nil {
+			log.Printf("Error purging expired idempotency keys: %v", err)
+		} else if removed > 0 {
+			log.Printf("Purged %d expired idempotency keys", removed)
+		}
+	}
+}
+
 func main() {
+	syncMode := flag.Bool("sync", false, "apply the SWIFT CSV file to an already populated table (insert, update and remove records)")
+	rejectsFile := flag.String("rejects", "", "write the rows rejected while parsing the SWIFT CSV file to this CSV file")
+	columnsFile := flag.String("columns", "", "JSON file with extra header names for the columns of the SWIFT CSV file")
+	encodingName := flag.String("encoding", "auto", "encoding of the SWIFT CSV file: auto, utf-8, windows-1250 or iso-8859-1")
+	duplicatesName := flag.String("duplicates", "first", "record kept for a SWIFT code repeated in the SWIFT CSV file: first, last or reject (load neither)")
+	idempotencyWindow := flag.Duration("idempotency-window", 24*time.Hour, "how long responses to requests with an Idempotency-Key header are replayed to retries")
+	flag.Parse()
+
+	encoding, err := services.ParseEncoding(*encodingName)
+	if err != nil {
+		log.Fatalf("Invalid -encoding: %v", err)
+	}
+	policy, err := services.ParseDuplicatePolicy(*duplicatesName)
+	if err != nil {
+		log.Fatalf("Invalid -duplicates: %v", err)
+	}
+	if *idempotencyWindow <= 0 {
+		log.Fatalf("Invalid -idempotency-window: %v (must be positive)", *idempotencyWindow)
+	}
+
 	// Retrieve environment variables
 	envVars := getEnv("DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME")
 
@@ -31,24 +160,45 @@ func main() {
 		envVars["DB_HOST"], envVars["DB_USER"], envVars["DB_PASSWORD"], envVars["DB_NAME"], envVars["DB_PORT"])
 	db.InitDatabase(dsn)
 	defer db.CloseDatabase()
+
+	// `main migrate up|down|status` manages the schema and exits
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration command failed: %v", err)
+		}
+		return
+	}
+
 	db.MigrateDatabase()
 
-	// Load data if the table is empty
-	if isEmpty, err := db.IsTableEmpty("swift_codes"); err != nil {
+	if *syncMode {
+		// Synchronise the table with the current directory file
+		log.Println("Sync mode enabled. Parsing data...")
+		swiftCodes, err := parseSwiftCodesFile(*columnsFile, *rejectsFile, encoding, policy)
+		if err != nil {
+			log.Fatalf("Error parsing SWIFT codes: %v", err)
+		}
+		result, err := services.SyncSwiftCodesToDatabase(db.DB, swiftCodes)
+		if err != nil {
+			log.Fatalf("Error synchronising SWIFT codes: %v", err)
+		}
+		log.Printf("Sync completed: %d added, %d changed, %d removed", result.Added, result.Changed, result.Removed)
+	} else if isEmpty, err := db.IsTableEmpty("swift_codes"); err != nil {
+		// Load data if the table is empty
 		log.Fatalf("Error checking table `swift_codes`: %v", err)
 	} else if isEmpty {
-		log.Println("Table `swift_codes` is empty. Parsing data...")
-		if swiftCodes, err := services.ParseSwiftCodes("data/swift_codes.csv"); err != nil {
-			log.Fatalf("Error parsing SWIFT codes: %v", err)
-		} else if err := services.SaveSwiftCodesToDatabase(db.DB, swiftCodes); err != nil {
-			log.Fatalf("Error saving SWIFT codes to database: %v", err)
+		log.Println("Table `swift_codes` is empty. Loading data...")
+		if err := loadSwiftCodesFile(*columnsFile, *rejectsFile, encoding, policy); err != nil {
+			log.Fatalf("Error loading SWIFT codes into database: %v", err)
 		}
 		log.Println("Data successfully saved to the database!")
 	} else {
-		log.Println("Table `swift_codes` contains data. Skipping parsing.")
+		log.Println("Table `swift_codes` contains data. Skipping parsing. Run with -sync to apply a new file.")
 	}
 
+	go purgeIdempotencyKeys(repositories.NewIdempotencyKeyRepository(db.DB), *idempotencyWindow)
+
 	// Start the server
-	r := routes.SetupRouter(db.DB)
+	r := routes.SetupRouter(db.DB, *idempotencyWindow)
 	log.Fatal(r.Run(":8080"))
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/migrations"
+)
+
+// runMigrate handles the `migrate up|down|status` subcommand.
+func runMigrate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: main migrate up|down|status")
+	}
+
+	migrator, err := migrations.NewMigrator(db.DB)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", len(applied))
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			log.Println("No applied migrations to revert")
+			return nil
+		}
+		log.Printf("Reverted migration %d_%s", reverted.Version, reverted.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+	return nil
+}
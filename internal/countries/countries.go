@@ -0,0 +1,80 @@
+package countries
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed iso3166.tsv
+var iso3166 string
+
+// Country is an ISO 3166-1 country.
+type Country struct {
+	Alpha2 string
+	Alpha3 string
+	// Name is the canonical upper-case short name, the form used by the SWIFT directory.
+	Name    string
+	aliases []string
+}
+
+var byAlpha2 = mustLoad(iso3166)
+
+// Lookup returns the country with the given alpha-2 code (case-insensitive).
+func Lookup(alpha2 string) (Country, bool) {
+	country, ok := byAlpha2[strings.ToUpper(strings.TrimSpace(alpha2))]
+	return country, ok
+}
+
+// CanonicalName returns the canonical name of the country with the given alpha-2 code,
+// or the trimmed fallback when the code is unknown.
+func CanonicalName(alpha2, fallback string) string {
+	if country, ok := Lookup(alpha2); ok {
+		return country.Name
+	}
+	return strings.TrimSpace(fallback)
+}
+
+// MatchesName reports whether name is the canonical or an alternative name of the country.
+// Case and repeated whitespace are ignored.
+func (c Country) MatchesName(name string) bool {
+	normalized := normalizeName(name)
+	if normalized == c.Name {
+		return true
+	}
+	for _, alias := range c.aliases {
+		if normalized == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+}
+
+// mustLoad parses the embedded table; a malformed table is a programming error.
+func mustLoad(table string) map[string]Country {
+	countries := make(map[string]Country)
+	scanner := bufio.NewScanner(strings.NewReader(table))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 4 || len(fields[0]) != 2 {
+			panic(fmt.Sprintf("countries: malformed line %d: %q", line, text))
+		}
+
+		country := Country{Alpha2: fields[0], Alpha3: fields[1], Name: fields[2]}
+		if fields[3] != "" {
+			country.aliases = strings.Split(fields[3], "|")
+		}
+		countries[country.Alpha2] = country
+	}
+	return countries
+}
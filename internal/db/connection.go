@@ -1,12 +1,14 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver for database/sql
+	"github.com/mroczekDNF/swift-api/internal/migrations"
 )
 
 var DB *sql.DB // Global database connection
@@ -32,29 +34,19 @@ func InitDatabase(dsn string) {
 	log.Println("Database connection established")
 }
 
-// MigrateDatabase creates the swift_codes table if it does not exist
+// MigrateDatabase applies all pending schema migrations
 func MigrateDatabase() {
-	query := `
-	CREATE TABLE IF NOT EXISTS swift_codes (
-		id SERIAL PRIMARY KEY,
-		swift_code VARCHAR(11) UNIQUE NOT NULL,
-		bank_name TEXT NOT NULL,
-		address TEXT,
-		country_iso2 CHAR(2) NOT NULL,
-		country_name TEXT NOT NULL,
-		is_headquarter BOOLEAN NOT NULL,
-		headquarter_id INT
-	);
+	migrator, err := migrations.NewMigrator(DB)
+	if err != nil {
+		log.Fatalf("Error loading database migrations: %v", err)
+	}
 
-	-- Add an index on headquarter_id for faster branch lookups
-	CREATE INDEX IF NOT EXISTS idx_headquarter_id ON swift_codes (headquarter_id);
-	`
-	_, err := DB.Exec(query)
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
 		log.Fatalf("Database migration error: %v", err)
 	}
 
-	log.Println("Database migration completed successfully")
+	log.Printf("Database migration completed successfully (%d applied)", len(applied))
 }
 
 // CloseDatabase closes the database connection
@@ -1,39 +1,51 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
 )
 
 // DeleteSwiftCode handles DELETE /v1/swift-codes/{swift-code} requests.
+// A BIC8 addresses its <BIC8>XXX record. The If-Match header must hold the current ETag
+// of the record, see checkIfMatch.
 func (h *SwiftCodeHandler) DeleteSwiftCode(c *gin.Context) {
-	swiftCode := strings.ToUpper(strings.TrimSpace(c.Param("swift-code")))
+	swiftCode := bic.Canonical(c.Param("swift-code"))
 
-	swift, err := h.repo.GetBySwiftCode(swiftCode)
-	if err != nil {
-		log.Printf("Error retrieving SWIFT code: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error retrieving SWIFT code"})
-		return
-	}
-	if swift == nil {
-		c.JSON(http.StatusNotFound, gin.H{"message": "SWIFT code not found"})
-		return
-	}
+	err := h.repo.WithTx(c.Request.Context(), func(repo repositories.SwiftCodeRepositoryInterface) error {
+		swift, err := repo.GetBySwiftCode(swiftCode)
+		if err != nil {
+			log.Printf("Error retrieving SWIFT code: %v", err)
+			return &txError{http.StatusInternalServerError, CodeInternalError, "Error retrieving SWIFT code", err}
+		}
+		if swift == nil {
+			return &txError{http.StatusNotFound, CodeSwiftNotFound, "SWIFT code not found", nil}
+		}
+		if err := checkIfMatch(c, repo, swift); err != nil {
+			return err
+		}
 
-	if swift.IsHeadquarter {
-		if err := h.repo.DetachBranchesFromHeadquarter(swift.ID); err != nil {
-			log.Printf("Error detaching branches: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Error detaching branches"})
-			return
+		if swift.IsHeadquarter {
+			if err := repo.DetachBranchesFromHeadquarter(swift.ID); err != nil {
+				log.Printf("Error detaching branches: %v", err)
+				return &txError{http.StatusInternalServerError, CodeInternalError, "Error detaching branches", err}
+			}
 		}
-	}
 
-	if err := h.repo.DeleteSwiftCode(swiftCode); err != nil {
-		log.Printf("Error deleting SWIFT code: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error deleting SWIFT code"})
+		if err := repo.DeleteSwiftCode(swiftCode, swift.Version); errors.Is(err, repositories.ErrVersionConflict) {
+			return &txError{http.StatusPreconditionFailed, CodePreconditionFailed, "The SWIFT code was changed while it was being deleted", err}
+		} else if err != nil {
+			log.Printf("Error deleting SWIFT code: %v", err)
+			return &txError{http.StatusInternalServerError, CodeInternalError, "Error deleting SWIFT code", err}
+		}
+		return nil
+	})
+	if err != nil {
+		respondWithTxError(c, err)
 		return
 	}
 
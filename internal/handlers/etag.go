@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+)
+
+// swiftCodeETag returns the strong entity tag of the GET /v1/swift-codes/{swiftCode}
+// representation of swift. It is the record version; for a headquarter, whose
+// representation lists its branches, a hash of the branch codes and versions follows.
+func swiftCodeETag(swift *models.SwiftCode, branches []models.SwiftCode) string {
+	tag := strconv.FormatInt(swift.Version, 10)
+	if swift.IsHeadquarter {
+		versions := make([]string, 0, len(branches))
+		for _, branch := range branches {
+			versions = append(versions, branch.SwiftCode+":"+strconv.FormatInt(branch.Version, 10))
+		}
+		sort.Strings(versions)
+
+		hash := fnv.New64a()
+		for _, version := range versions {
+			hash.Write([]byte(version))
+			hash.Write([]byte{0})
+		}
+		tag += fmt.Sprintf("-%016x", hash.Sum64())
+	}
+	return `"` + tag + `"`
+}
+
+// etagMatches reports whether a comma-separated If-Match or If-None-Match header value
+// holds etag or "*". The weak comparison of If-None-Match ignores the W/ prefix; the
+// strong comparison of If-Match never matches weak tags.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch evaluates the If-Match header required by mutations of a single SWIFT code
+// against the stored record. Without the header the request is refused with 428, and with
+// a tag other than the current one with 412, so that a client cannot overwrite changes it
+// has not seen. The repository must be the one of the transaction applying the change.
+func checkIfMatch(c *gin.Context, repo repositories.SwiftCodeRepositoryInterface, swift *models.SwiftCode) error {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return &txError{http.StatusPreconditionRequired, CodePreconditionRequired,
+			"Changing a SWIFT code requires its current ETag in the If-Match header", nil}
+	}
+	if strings.TrimSpace(ifMatch) == "*" {
+		return nil
+	}
+
+	etag, err := currentETag(repo, swift)
+	if err != nil {
+		return err
+	}
+	if !etagMatches(ifMatch, etag, false) {
+		return &txError{http.StatusPreconditionFailed, CodePreconditionFailed,
+			"The SWIFT code was changed since it was read; fetch it again to get the current ETag", nil}
+	}
+	return nil
+}
+
+// currentETag returns the ETag of the stored record swift, fetching the branches of a
+// headquarter. Failures are returned as *txError.
+func currentETag(repo repositories.SwiftCodeRepositoryInterface, swift *models.SwiftCode) (string, error) {
+	var branches []models.SwiftCode
+	if swift.IsHeadquarter {
+		var err error
+		if branches, err = repo.GetBranchesByHeadquarter(swift.SwiftCode); err != nil && err != sql.ErrNoRows {
+			log.Println("Error fetching branches:", err)
+			return "", &txError{http.StatusInternalServerError, CodeInternalError, "Error fetching branches", err}
+		}
+	}
+	return swiftCodeETag(swift, branches), nil
+}
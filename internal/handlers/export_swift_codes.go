@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/services"
+)
+
+// exportPageSize is the number of rows fetched from the database per keyset page while exporting.
+const exportPageSize = 1000
+
+// exportWriter encodes exported rows in one of the supported formats.
+type exportWriter interface {
+	WriteSwiftCode(code models.SwiftCode) error
+	Flush() error
+}
+
+// ExportSwiftCodes handles GET /v1/swift-codes/export?format=csv|ndjson&country= requests.
+// Rows are streamed page by page in SWIFT code order, so the directory is never held in memory.
+// CSV uses the layout of the directory file, so an export can be imported elsewhere.
+func (h *SwiftCodeHandler) ExportSwiftCodes(c *gin.Context) {
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "csv")))
+	if format != "csv" && format != "ndjson" {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, "Invalid 'format' value. Must be csv or ndjson.")
+		return
+	}
+
+	filter := repositories.SwiftCodeFilter{SortBy: "swiftCode", Limit: exportPageSize}
+	if value := c.Query("country"); value != "" {
+		country, ok := countries.Lookup(value)
+		if !ok {
+			respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, "Invalid 'country' value. Must be an ISO 3166 country code.")
+			return
+		}
+		filter.CountryISO2 = country.Alpha2
+	}
+
+	// The first page is read before anything is written, so that a failing query still gets an error response.
+	page, err := h.repo.ListSwiftCodes(filter)
+	if err != nil {
+		log.Println("Error exporting SWIFT codes:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error fetching SWIFT codes")
+		return
+	}
+
+	var writer exportWriter
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="swift-codes.csv"`)
+		writer = newCSVExportWriter(c.Writer)
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="swift-codes.ndjson"`)
+		writer = &ndjsonExportWriter{encoder: json.NewEncoder(c.Writer)}
+	}
+	c.Status(http.StatusOK)
+
+	for {
+		for _, code := range page {
+			if err := writer.WriteSwiftCode(code); err != nil {
+				log.Println("Error writing export row:", err)
+				return
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			log.Println("Error writing export:", err)
+			return
+		}
+		c.Writer.Flush()
+
+		if len(page) < exportPageSize {
+			return
+		}
+
+		// The status has been sent by now: a failure can only cut the stream short.
+		last := page[len(page)-1]
+		filter.After = &repositories.SwiftCodeCursor{SortValue: last.SwiftCode, SwiftCode: last.SwiftCode}
+		if page, err = h.repo.ListSwiftCodes(filter); err != nil {
+			log.Println("Error exporting SWIFT codes after", last.SwiftCode+":", err)
+			return
+		}
+	}
+}
+
+// csvExportWriter writes rows in the directory file layout, starting with its header.
+type csvExportWriter struct {
+	writer *csv.Writer
+	err    error
+}
+
+func newCSVExportWriter(w http.ResponseWriter) *csvExportWriter {
+	writer := csv.NewWriter(w)
+	return &csvExportWriter{writer: writer, err: writer.Write(services.CSVHeader)}
+}
+
+func (w *csvExportWriter) WriteSwiftCode(code models.SwiftCode) error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.writer.Write(services.CSVRecord(code))
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonExportWriter writes one JSON object per line, with the fields of the list endpoint.
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonExportWriter) WriteSwiftCode(code models.SwiftCode) error {
+	return w.encoder.Encode(gin.H{
+		"address":       code.Address,
+		"bankName":      code.BankName,
+		"codeType":      code.CodeType,
+		"countryISO2":   code.CountryISO2,
+		"countryName":   countries.CanonicalName(code.CountryISO2, code.CountryName),
+		"isHeadquarter": code.IsHeadquarter,
+		"swiftCode":     code.SwiftCode,
+		"timeZone":      code.TimeZone,
+		"townName":      code.TownName,
+	})
+}
+
+func (w *ndjsonExportWriter) Flush() error {
+	return nil
+}
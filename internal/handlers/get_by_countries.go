@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
 	"github.com/mroczekDNF/swift-api/internal/models"
 )
 
@@ -14,14 +17,18 @@ func (h *SwiftCodeHandler) GetSwiftCodesByCountry(c *gin.Context) {
 	countryISO2 := strings.ToUpper(strings.TrimSpace(c.Param("countryISO2")))
 
 	swiftCodes, err := h.repo.GetByCountryISO2(countryISO2)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(c, http.StatusNotFound, CodeCountryNotFound, "No SWIFT codes found for the given country")
+		return
+	}
 	if err != nil {
 		log.Println("Error fetching SWIFT codes:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching SWIFT codes"})
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error fetching SWIFT codes")
 		return
 	}
 
 	if len(swiftCodes) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No SWIFT codes found for the given country"})
+		respondWithError(c, http.StatusNotFound, CodeCountryNotFound, "No SWIFT codes found for the given country")
 		return
 	}
 
@@ -31,16 +38,19 @@ func (h *SwiftCodeHandler) GetSwiftCodesByCountry(c *gin.Context) {
 
 // formatSwiftCodesResponse formats the SWIFT codes into the expected response structure
 func formatSwiftCodesResponse(countryISO2 string, swiftCodes []models.SwiftCode) gin.H {
-	countryName := swiftCodes[0].CountryName
+	countryName := countries.CanonicalName(countryISO2, swiftCodes[0].CountryName)
 
 	var formattedSwiftCodes []gin.H
 	for _, code := range swiftCodes {
 		formattedSwiftCodes = append(formattedSwiftCodes, gin.H{
 			"address":       code.Address,
 			"bankName":      code.BankName,
+			"codeType":      code.CodeType,
 			"countryISO2":   code.CountryISO2,
 			"isHeadquarter": code.IsHeadquarter,
 			"swiftCode":     code.SwiftCode,
+			"timeZone":      code.TimeZone,
+			"townName":      code.TownName,
 		})
 	}
 
@@ -4,24 +4,41 @@ import (
 	"database/sql"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
 )
 
 // GetSwiftCodeDetails returns details for a given SWIFT code.
+// The code is matched case-insensitively and a BIC8 resolves to its <BIC8>XXX headquarter.
+// With ?fallback=true an unknown branch code falls back to its headquarter,
+// flagged with "fallback": true in the response.
+// The response carries an ETag; a request whose If-None-Match holds it is answered with 304.
 func (h *SwiftCodeHandler) GetSwiftCodeDetails(c *gin.Context) {
-	swiftCode := strings.TrimSpace(c.Param("swiftCode"))
+	requested := strings.ToUpper(strings.TrimSpace(c.Param("swiftCode")))
+
+	fallback := false
+	if value := c.Query("fallback"); value != "" {
+		var err error
+		if fallback, err = strconv.ParseBool(value); err != nil {
+			respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, "Invalid 'fallback' value. Must be true or false.")
+			return
+		}
+	}
 
 	// Fetch details for the given SWIFT code
-	swift, err := h.repo.GetBySwiftCode(swiftCode)
+	swift, usedFallback, err := h.resolveSwiftCode(requested, fallback)
 	if err != nil {
 		log.Println("Error fetching SWIFT code:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching data"})
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error fetching data")
 		return
 	}
 	if swift == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "SWIFT code not found"})
+		respondWithError(c, http.StatusNotFound, CodeSwiftNotFound, "SWIFT code not found")
 		return
 	}
 
@@ -31,16 +48,24 @@ func (h *SwiftCodeHandler) GetSwiftCodeDetails(c *gin.Context) {
 		"bankName":      swift.BankName,
 		"address":       swift.Address,
 		"countryISO2":   swift.CountryISO2,
-		"countryName":   swift.CountryName,
+		"countryName":   countries.CanonicalName(swift.CountryISO2, swift.CountryName),
 		"isHeadquarter": swift.IsHeadquarter,
+		"codeType":      swift.CodeType,
+		"townName":      swift.TownName,
+		"timeZone":      swift.TimeZone,
+	}
+	if usedFallback {
+		response["fallback"] = true
+		response["requestedSwiftCode"] = requested
 	}
 
 	// If it's a headquarters, add branches to the response
+	var branches []models.SwiftCode
 	if swift.IsHeadquarter {
-		branches, err := h.repo.GetBranchesByHeadquarter(swift.SwiftCode)
+		branches, err = h.repo.GetBranchesByHeadquarter(swift.SwiftCode)
 		if err != nil && err != sql.ErrNoRows {
 			log.Println("Error fetching branches:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching branches"})
+			respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error fetching branches")
 			return
 		}
 
@@ -52,11 +77,41 @@ func (h *SwiftCodeHandler) GetSwiftCodeDetails(c *gin.Context) {
 				"bankName":    branch.BankName,
 				"address":     branch.Address,
 				"countryISO2": branch.CountryISO2,
-				"countryName": branch.CountryName,
+				"countryName": countries.CanonicalName(branch.CountryISO2, branch.CountryName),
+				"codeType":    branch.CodeType,
+				"townName":    branch.TownName,
+				"timeZone":    branch.TimeZone,
 			})
 		}
 		response["branches"] = branchList
 	}
 
+	etag := swiftCodeETag(swift, branches)
+	c.Header("ETag", etag)
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, response)
 }
+
+// resolveSwiftCode looks up an upper-cased SWIFT code. Valid BICs are looked up in their
+// 11-character form, so a BIC8 finds its primary office. When fallback is set and a branch
+// code is unknown, its headquarter is returned instead and the second result is true.
+func (h *SwiftCodeHandler) resolveSwiftCode(code string, fallback bool) (*models.SwiftCode, bool, error) {
+	parsed, err := bic.Parse(code)
+	if err != nil {
+		// Not a valid BIC, but legacy rows may still match it verbatim.
+		swift, err := h.repo.GetBySwiftCode(code)
+		return swift, false, err
+	}
+
+	swift, err := h.repo.GetBySwiftCode(parsed.BIC11())
+	if err != nil || swift != nil || !fallback || parsed.IsPrimaryOffice() {
+		return swift, false, err
+	}
+
+	swift, err = h.repo.GetBySwiftCode(parsed.PrimaryOffice().String())
+	return swift, swift != nil, err
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+)
+
+// Headers of idempotent requests.
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"     // Client-chosen key that makes a mutating request safe to retry
+	IdempotentReplayedHeader = "Idempotent-Replayed" // Set on responses replayed from an earlier request
+)
+
+// idempotencyKeyPattern accepts 1 to 255 printable ASCII characters, e.g. a UUID.
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7E]{1,255}$`)
+
+// maxIdempotentRequestSize limits the body of a request with an Idempotency-Key, which is
+// held in memory to be hashed before the handler runs.
+const maxIdempotentRequestSize = 8 << 20
+
+// responseRecorder copies the response body written by a handler.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes mutating requests sent with an Idempotency-Key header safe to retry.
+// It is attached to the mutating routes only.
+// The first request with a key is processed and its response stored for window; a retry
+// with the same method, path, If-Match header and body gets the stored response replayed,
+// with its ETag and marked with the Idempotent-Replayed header, instead of being processed
+// again. Reusing a key for a different request is rejected with 422, and a retry arriving
+// while the first request is still being processed with 409. Server errors are not stored,
+// so such requests can be retried.
+func Idempotency(repo repositories.IdempotencyKeyRepositoryInterface, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !idempotencyKeyPattern.MatchString(key) {
+			respondWithError(c, http.StatusBadRequest, CodeInvalidIdempotencyKey,
+				"The Idempotency-Key header must be 1 to 255 printable ASCII characters")
+			return
+		}
+
+		// The body is read up front to tell a retry from a different request with the same key.
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentRequestSize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondWithError(c, http.StatusRequestEntityTooLarge, CodeRequestTooLarge,
+					"Requests with an Idempotency-Key must not exceed "+strconv.Itoa(maxIdempotentRequestSize>>20)+" MB")
+				return
+			}
+			respondWithError(c, http.StatusBadRequest, CodeInvalidRequest, "Could not read the request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashRequest(c.Request, body)
+
+		stored, err := repo.ReserveKey(key, requestHash, window)
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error checking the Idempotency-Key")
+			return
+		}
+		if stored != nil {
+			replayResponse(c, stored, requestHash)
+			return
+		}
+
+		// Release the key unless a response is stored, also when the handler panics.
+		saved := false
+		defer func() {
+			if !saved {
+				if err := repo.ReleaseKey(key); err != nil {
+					log.Printf("Idempotency-Key %q stays reserved until it expires: %v", key, err)
+				}
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if status := recorder.Status(); status < http.StatusInternalServerError {
+			header := recorder.Header()
+			saved = repo.SaveResponse(key, status, header.Get("Content-Type"), header.Get("ETag"), recorder.body.Bytes()) == nil
+		}
+	}
+}
+
+// replayResponse answers a request whose key is already stored.
+func replayResponse(c *gin.Context, stored *models.IdempotencyKey, requestHash string) {
+	switch {
+	case stored.RequestHash != requestHash:
+		respondWithError(c, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused,
+			"The Idempotency-Key was already used for a different request")
+	case stored.StatusCode == nil:
+		c.Header("Retry-After", "1")
+		respondWithError(c, http.StatusConflict, CodeIdempotencyKeyInProgress,
+			"A request with this Idempotency-Key is still being processed")
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		if stored.ETag != "" {
+			c.Header("ETag", stored.ETag)
+		}
+		c.Data(*stored.StatusCode, stored.ContentType, stored.ResponseBody)
+		c.Abort()
+	}
+}
+
+// hashRequest returns the hex SHA-256 hash of the method, path with query, If-Match header
+// and body of a request. If-Match is included because it decides whether an update applies.
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	io.WriteString(hash, "If-Match: "+r.Header.Get("If-Match")+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/services"
+)
+
+// maxImportSize limits the size of an uploaded import request body.
+const maxImportSize = 64 << 20
+
+// ImportHandler handles uploads of SWIFT directory files.
+type ImportHandler struct {
+	db *sql.DB
+}
+
+// NewImportHandler creates a new import handler.
+func NewImportHandler(db *sql.DB) *ImportHandler {
+	return &ImportHandler{db: db}
+}
+
+// ImportResult reports what an import changed, or would change in a dry run.
+type ImportResult struct {
+	DryRun             bool                       `json:"dryRun"`
+	Encoding           services.Encoding          `json:"encoding"`
+	TotalRecords       int                        `json:"totalRecords"`
+	Inserted           int                        `json:"inserted"`
+	Updated            int                        `json:"updated"`
+	Unchanged          int                        `json:"unchanged"`
+	Rejected           int                        `json:"rejected"`
+	InsertedSwiftCodes []string                   `json:"insertedSwiftCodes"`
+	UpdatedSwiftCodes  []string                   `json:"updatedSwiftCodes"`
+	RejectedRecords    []services.RejectedRecord  `json:"rejectedRecords"`
+	DuplicatePolicy    services.DuplicatePolicy   `json:"duplicatePolicy"`
+	Duplicates         []services.DuplicateRecord `json:"duplicates"`
+	Discarded          []string                   `json:"discardedSwiftCodes"`
+}
+
+// ImportSwiftCodes handles POST /v1/imports requests with a CSV file in the "file" form field.
+// Columns are found by header name; an optional "columns" form field holds extra header
+// aliases as JSON (see services.LoadColumnMapping) and an optional "encoding" form field declares
+// the encoding of the file, which is detected otherwise. The optional "duplicates" form field
+// selects how SWIFT codes repeated in the file are resolved (first, last or reject). New codes are inserted and changed ones
+// updated; codes missing from the file are kept. With ?dryRun=true nothing is written.
+func (h *ImportHandler) ImportSwiftCodes(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dryRun"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, "Invalid 'dryRun' value. Must be true or false.")
+			return
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(c, http.StatusRequestEntityTooLarge, CodeImportFileTooLarge,
+				"The import file must not exceed "+strconv.Itoa(maxImportSize>>20)+" MB")
+			return
+		}
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"file", FieldCodeRequired, "A CSV file must be uploaded in the 'file' form field."})
+		return
+	}
+
+	mapping := services.DefaultColumnMapping
+	if value := c.PostForm("columns"); value != "" {
+		if mapping, err = services.ParseColumnMapping([]byte(value)); err != nil {
+			respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+				FieldError{"columns", FieldCodeInvalid, err.Error()})
+			return
+		}
+	}
+
+	encoding, err := services.ParseEncoding(c.PostForm("encoding"))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"encoding", FieldCodeInvalid, err.Error()})
+		return
+	}
+
+	policy, err := services.ParseDuplicatePolicy(c.PostForm("duplicates"))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"duplicates", FieldCodeInvalid, err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Println("Error opening uploaded file:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error reading the uploaded file")
+		return
+	}
+	defer file.Close()
+
+	input, encoding, err := services.DecodeInput(file, encoding)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidImportFile, "Invalid CSV file: "+err.Error())
+		return
+	}
+
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(input, mapping, policy)
+	if errors.Is(err, io.EOF) {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidImportFile, "The uploaded file is empty")
+		return
+	}
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidImportFile, "Invalid CSV file: "+err.Error())
+		return
+	}
+
+	plan, err := services.ImportSwiftCodes(h.db, swiftCodes, dryRun)
+	if err != nil {
+		log.Println("Error importing SWIFT codes:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error importing SWIFT codes")
+		return
+	}
+
+	result := ImportResult{
+		DryRun:             dryRun,
+		Encoding:           encoding,
+		TotalRecords:       report.Total,
+		Inserted:           len(plan.ToInsert),
+		Updated:            len(plan.ToUpdate),
+		Unchanged:          plan.Unchanged,
+		Rejected:           len(report.Rejected),
+		InsertedSwiftCodes: make([]string, 0, len(plan.ToInsert)),
+		UpdatedSwiftCodes:  make([]string, 0, len(plan.ToUpdate)),
+		RejectedRecords:    report.Rejected,
+		DuplicatePolicy:    policy,
+		Duplicates:         report.Duplicates,
+		Discarded:          report.Discarded,
+	}
+	for _, code := range plan.ToInsert {
+		result.InsertedSwiftCodes = append(result.InsertedSwiftCodes, code.SwiftCode)
+	}
+	for _, code := range plan.ToUpdate {
+		result.UpdatedSwiftCodes = append(result.UpdatedSwiftCodes, code.SwiftCode)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHeadquarterLinkIssues reports branches whose headquarter_id points at the wrong record.
+func (h *SwiftCodeHandler) GetHeadquarterLinkIssues(c *gin.Context) {
+	issues, err := h.repo.FindHeadquarterLinkIssues()
+	if err != nil {
+		log.Println("Error checking headquarter links:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error checking headquarter links")
+		return
+	}
+
+	formattedIssues := make([]gin.H, 0, len(issues))
+	for _, issue := range issues {
+		formattedIssues = append(formattedIssues, gin.H{
+			"swiftCode":           issue.SwiftCode,
+			"headquarterId":       issue.HeadquarterID,
+			"linkedSwiftCode":     issue.LinkedSwiftCode,
+			"expectedHeadquarter": issue.ExpectedHeadquarter,
+			"problem":             issue.Problem,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":  len(issues),
+		"issues": formattedIssues,
+	})
+}
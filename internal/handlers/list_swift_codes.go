@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+	defaultSortBy   = "swiftCode"
+)
+
+// listCursor is the opaque position handed out in "next" links.
+type listCursor struct {
+	Sort      string `json:"s"`
+	SortValue string `json:"v"`
+	SwiftCode string `json:"c"`
+}
+
+// ListSwiftCodes handles GET /v1/swift-codes requests with filtering, sorting and cursor pagination.
+func (h *SwiftCodeHandler) ListSwiftCodes(c *gin.Context) {
+	filter, err := parseListFilter(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, err.Error())
+		return
+	}
+
+	pageSize := filter.Limit
+	// Fetch one extra row to find out whether another page exists.
+	filter.Limit++
+
+	swiftCodes, err := h.repo.ListSwiftCodes(filter)
+	if err != nil {
+		log.Println("Error listing SWIFT codes:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error fetching SWIFT codes")
+		return
+	}
+
+	var next, nextCursor interface{}
+	if len(swiftCodes) > pageSize {
+		swiftCodes = swiftCodes[:pageSize]
+		cursor := encodeListCursor(c.Query("sort"), filter.SortBy, swiftCodes[pageSize-1])
+		nextCursor = cursor
+
+		query := c.Request.URL.Query()
+		query.Set("cursor", cursor)
+		next = c.Request.URL.Path + "?" + query.Encode()
+	}
+
+	formattedSwiftCodes := make([]gin.H, 0, len(swiftCodes))
+	for _, code := range swiftCodes {
+		formattedSwiftCodes = append(formattedSwiftCodes, gin.H{
+			"address":       code.Address,
+			"bankName":      code.BankName,
+			"codeType":      code.CodeType,
+			"countryISO2":   code.CountryISO2,
+			"countryName":   countries.CanonicalName(code.CountryISO2, code.CountryName),
+			"isHeadquarter": code.IsHeadquarter,
+			"swiftCode":     code.SwiftCode,
+			"timeZone":      code.TimeZone,
+			"townName":      code.TownName,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"swiftCodes": formattedSwiftCodes,
+		"next":       next,
+		"nextCursor": nextCursor,
+	})
+}
+
+// parseListFilter builds the repository filter from the query string.
+func parseListFilter(c *gin.Context) (repositories.SwiftCodeFilter, error) {
+	filter := repositories.SwiftCodeFilter{
+		CountryISO2: strings.ToUpper(strings.TrimSpace(c.Query("country"))),
+		BankName:    strings.TrimSpace(c.Query("bankName")),
+		Town:        strings.TrimSpace(c.Query("town")),
+		CodePrefix:  strings.ToUpper(strings.TrimSpace(c.Query("prefix"))),
+		SortBy:      defaultSortBy,
+		Limit:       defaultPageSize,
+	}
+
+	if value := c.Query("isHeadquarter"); value != "" {
+		isHeadquarter, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New("Invalid 'isHeadquarter' value. Must be true or false.")
+		}
+		filter.IsHeadquarter = &isHeadquarter
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		filter.Descending = strings.HasPrefix(sort, "-")
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+		if _, ok := repositories.SortColumns[filter.SortBy]; !ok {
+			return filter, errors.New("Invalid 'sort' value. Must be one of swiftCode, bankName, countryISO2, optionally prefixed with '-'.")
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return filter, errors.New("Invalid 'limit' value. Must be between 1 and 500.")
+		}
+		filter.Limit = limit
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := decodeListCursor(value)
+		if err != nil || cursor.Sort != c.Query("sort") {
+			return filter, errors.New("Invalid 'cursor' value.")
+		}
+		filter.After = &repositories.SwiftCodeCursor{SortValue: cursor.SortValue, SwiftCode: cursor.SwiftCode}
+	}
+
+	return filter, nil
+}
+
+// encodeListCursor returns the cursor pointing just after the given row.
+func encodeListCursor(sort, sortBy string, last models.SwiftCode) string {
+	cursor := listCursor{Sort: sort, SwiftCode: last.SwiftCode}
+	switch sortBy {
+	case "bankName":
+		cursor.SortValue = last.BankName
+	case "countryISO2":
+		cursor.SortValue = last.CountryISO2
+	default:
+		cursor.SortValue = last.SwiftCode
+	}
+
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeListCursor(value string) (listCursor, error) {
+	var cursor listCursor
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(payload, &cursor)
+	return cursor, err
+}
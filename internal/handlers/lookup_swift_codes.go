@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
+)
+
+// maxLookupCodes limits the number of SWIFT codes resolved by a single lookup request.
+const maxLookupCodes = 1000
+
+// Per-code lookup result statuses.
+const (
+	LookupStatusFound    = "FOUND"
+	LookupStatusNotFound = "NOT_FOUND"
+	LookupStatusInvalid  = "INVALID"
+)
+
+// LookupRequest is the body of POST /v1/swift-codes/lookup.
+type LookupRequest struct {
+	SwiftCodes []string `json:"swiftCodes" binding:"required"`
+}
+
+// LookupSwiftCodes handles POST /v1/swift-codes/lookup requests.
+// Every requested code gets a result, in request order. Codes are resolved like
+// GET /v1/swift-codes/:swiftCode: case-insensitively, with a BIC8 meaning its XXX headquarter.
+func (h *SwiftCodeHandler) LookupSwiftCodes(c *gin.Context) {
+	var request LookupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondWithBindingError(c, err)
+		return
+	}
+
+	if len(request.SwiftCodes) > maxLookupCodes {
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"swiftCodes", FieldCodeInvalid, "At most " + strconv.Itoa(maxLookupCodes) + " SWIFT codes can be looked up at once."})
+		return
+	}
+
+	// Parse every code first so that the database is queried once, for distinct valid codes only.
+	parsed := make([]*bic.BIC, len(request.SwiftCodes))
+	parseErrors := make([]error, len(request.SwiftCodes))
+	queryCodes := make([]string, 0, len(request.SwiftCodes))
+	queued := make(map[string]bool)
+	for i, code := range request.SwiftCodes {
+		swiftCode, err := bic.Parse(code)
+		if err != nil {
+			parseErrors[i] = err
+			continue
+		}
+		parsed[i] = &swiftCode
+		if !queued[swiftCode.BIC11()] {
+			queued[swiftCode.BIC11()] = true
+			queryCodes = append(queryCodes, swiftCode.BIC11())
+		}
+	}
+
+	found := make(map[string]models.SwiftCode)
+	if len(queryCodes) > 0 {
+		swiftCodes, err := h.repo.GetBySwiftCodes(queryCodes)
+		if err != nil {
+			log.Println("Error looking up SWIFT codes:", err)
+			respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error fetching data")
+			return
+		}
+		for _, swift := range swiftCodes {
+			found[swift.SwiftCode] = swift
+		}
+	}
+
+	results := make([]gin.H, 0, len(request.SwiftCodes))
+	for i, code := range request.SwiftCodes {
+		result := gin.H{"swiftCode": strings.ToUpper(strings.TrimSpace(code))}
+		if parsed[i] == nil {
+			result["status"] = LookupStatusInvalid
+			result["error"] = swiftCodeErrorMessage(parseErrors[i])
+		} else if swift, ok := found[parsed[i].BIC11()]; !ok {
+			result["status"] = LookupStatusNotFound
+		} else {
+			result["status"] = LookupStatusFound
+			result["data"] = gin.H{
+				"swiftCode":     swift.SwiftCode,
+				"bankName":      swift.BankName,
+				"address":       swift.Address,
+				"countryISO2":   swift.CountryISO2,
+				"countryName":   countries.CanonicalName(swift.CountryISO2, swift.CountryName),
+				"isHeadquarter": swift.IsHeadquarter,
+				"codeType":      swift.CodeType,
+				"townName":      swift.TownName,
+				"timeZone":      swift.TimeZone,
+			}
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -2,77 +2,97 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"regexp"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
 	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
 )
 
 type SwiftCodeRequest struct {
 	Address       string `json:"address"`
 	BankName      string `json:"bankName" binding:"required"`
+	CodeType      string `json:"codeType"`
 	CountryISO2   string `json:"countryISO2" binding:"required"`
 	CountryName   string `json:"countryName" binding:"required"`
 	IsHeadquarter *bool  `json:"isHeadquarter" binding:"required"`
 	SwiftCode     string `json:"swiftCode" binding:"required"`
+	TimeZone      string `json:"timeZone"`
+	TownName      string `json:"townName"`
 }
 
 func (h *SwiftCodeHandler) AddSwiftCode(c *gin.Context) {
 	var request SwiftCodeRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		respondWithError(c, http.StatusBadRequest, "Invalid request structure", err.Error())
+		respondWithBindingError(c, err)
 		return
 	}
 
 	if request.IsHeadquarter == nil {
-		respondWithError(c, http.StatusBadRequest, "Field 'isHeadquarter' is required")
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"isHeadquarter", FieldCodeRequired, "Field 'isHeadquarter' is required"})
 		return
 	}
 
 	normalizeSwiftCodeRequest(&request)
 
 	if err := validateSwiftCodeRequest(&request); err != nil {
-		respondWithError(c, http.StatusBadRequest, err.Error())
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed", err.Errors...)
 		return
 	}
 
-	existingCode, err := h.repo.GetBySwiftCode(request.SwiftCode)
+	err := h.repo.WithTx(c.Request.Context(), func(repo repositories.SwiftCodeRepositoryInterface) error {
+		return insertSwiftCodeRequest(repo, &request)
+	})
 	if err != nil {
-		log.Println("Error checking if SWIFT code exists:", err)
-		respondWithError(c, http.StatusInternalServerError, "Error checking data")
+		respondWithTxError(c, err)
 		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "SWIFT code added successfully"})
+}
+
+// insertSwiftCodeRequest stores a validated request and links it to its headquarter or branches.
+// Failures are returned as *txError; an existing code is reported as CodeDuplicateSwiftCode.
+func insertSwiftCodeRequest(repo repositories.SwiftCodeRepositoryInterface, request *SwiftCodeRequest) error {
+	existingCode, err := repo.GetBySwiftCode(request.SwiftCode)
+	if err != nil {
+		log.Println("Error checking if SWIFT code exists:", err)
+		return &txError{http.StatusInternalServerError, CodeInternalError, "Error checking data", err}
+	}
 	if existingCode != nil {
-		respondWithError(c, http.StatusConflict, "SWIFT code already exists in the database")
-		return
+		return &txError{http.StatusConflict, CodeDuplicateSwiftCode, "SWIFT code already exists in the database", nil}
 	}
 
-	newSwiftCode := createSwiftCodeModel(&request)
-	if err := assignHeadquarterID(h, &newSwiftCode, request.SwiftCode); err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Error finding headquarter")
-		return
+	newSwiftCode := createSwiftCodeModel(request)
+	if err := assignHeadquarterID(repo, &newSwiftCode, request.SwiftCode); err != nil {
+		return &txError{http.StatusInternalServerError, CodeInternalError, "Error finding headquarter", err}
 	}
 
-	if err := h.repo.InsertSwiftCode(&newSwiftCode); err != nil {
+	// The check above does not stop a concurrent request inserting the same code first;
+	// the unique constraint does, and is reported the same way.
+	if err := repo.InsertSwiftCode(&newSwiftCode); errors.Is(err, repositories.ErrDuplicateSwiftCode) {
+		return &txError{http.StatusConflict, CodeDuplicateSwiftCode, "SWIFT code already exists in the database", err}
+	} else if err != nil {
 		log.Println("Error saving SWIFT code:", err)
-		respondWithError(c, http.StatusInternalServerError, "Error saving SWIFT code")
-		return
+		return &txError{http.StatusInternalServerError, CodeInternalError, "Error saving SWIFT code", err}
 	}
 
 	// Jeśli dodaliśmy headquarter, sprawdzamy, czy są branche do przypisania
 	if *request.IsHeadquarter {
-		err = h.repo.AssignBranchesToHeadquarter(request.SwiftCode)
+		err = repo.AssignBranchesToHeadquarter(request.SwiftCode)
 		if err != nil && err != sql.ErrNoRows {
 			log.Println("Error assigning branches to headquarter:", err)
-			respondWithError(c, http.StatusInternalServerError, "Error assigning branches to headquarter")
-			return
+			return &txError{http.StatusInternalServerError, CodeInternalError, "Error assigning branches to headquarter", err}
 		}
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "SWIFT code added successfully"})
+	return nil
 }
 
 func normalizeSwiftCodeRequest(request *SwiftCodeRequest) {
@@ -81,38 +101,86 @@ func normalizeSwiftCodeRequest(request *SwiftCodeRequest) {
 	request.CountryName = strings.TrimSpace(request.CountryName)
 	request.BankName = strings.TrimSpace(request.BankName)
 	request.Address = strings.TrimSpace(request.Address)
+	request.CodeType = strings.ToUpper(strings.TrimSpace(request.CodeType))
+	request.TownName = strings.TrimSpace(request.TownName)
+	request.TimeZone = strings.TrimSpace(request.TimeZone)
 
 	if request.Address == "" {
 		request.Address = "UNKNOWN"
 	}
+
+	// Without an explicit code type, derive it from the code length.
+	if request.CodeType == "" {
+		request.CodeType = bic.CodeType(request.SwiftCode)
+	}
+	request.SwiftCode = bic.Canonical(request.SwiftCode)
 }
 
-func validateSwiftCodeRequest(request *SwiftCodeRequest) error {
-	if len(request.SwiftCode) < 8 || len(request.SwiftCode) > 11 {
-		return &ValidationError{"Invalid SWIFT code length. Must be between 8 and 11 characters."}
+// validateSwiftCodeRequest checks every field and reports all problems at once.
+func validateSwiftCodeRequest(request *SwiftCodeRequest) *ValidationError {
+	var fieldErrors []FieldError
+
+	swiftCode, err := bic.Parse(request.SwiftCode)
+	if err != nil {
+		fieldErrors = append(fieldErrors, FieldError{"swiftCode", FieldCodeInvalid, swiftCodeErrorMessage(err)})
+	} else if request.IsHeadquarter != nil && *request.IsHeadquarter != swiftCode.IsPrimaryOffice() {
+		fieldErrors = append(fieldErrors, FieldError{"isHeadquarter", FieldCodeInvalid, "Field 'isHeadquarter' must be true exactly when the SWIFT code ends with XXX."})
 	}
 
-	if len(request.CountryISO2) != 2 || !regexp.MustCompile(`^[A-Z]{2}$`).MatchString(request.CountryISO2) {
-		return &ValidationError{"Invalid country ISO2 code. Must be exactly 2 uppercase letters."}
+	country, countryKnown := countries.Lookup(request.CountryISO2)
+	switch {
+	case len(request.CountryISO2) != 2 || !regexp.MustCompile(`^[A-Z]{2}$`).MatchString(request.CountryISO2):
+		fieldErrors = append(fieldErrors, FieldError{"countryISO2", FieldCodeInvalid, "Invalid country ISO2 code. Must be exactly 2 uppercase letters."})
+	case !countryKnown:
+		fieldErrors = append(fieldErrors, FieldError{"countryISO2", FieldCodeInvalid, "Unknown country ISO2 code. Must be an ISO 3166 country."})
+	case err == nil && swiftCode.CountryCode() != request.CountryISO2:
+		fieldErrors = append(fieldErrors, FieldError{"swiftCode", FieldCodeInvalid, "Characters 5-6 of the SWIFT code must equal countryISO2 (" + request.CountryISO2 + ")."})
 	}
 
 	if request.BankName == "" {
-		return &ValidationError{"Bank name cannot be empty."}
+		fieldErrors = append(fieldErrors, FieldError{"bankName", FieldCodeRequired, "Bank name cannot be empty."})
 	}
 
 	if request.CountryName == "" {
-		return &ValidationError{"Country name cannot be empty."}
+		fieldErrors = append(fieldErrors, FieldError{"countryName", FieldCodeRequired, "Country name cannot be empty."})
+	} else if countryKnown && !country.MatchesName(request.CountryName) {
+		fieldErrors = append(fieldErrors, FieldError{"countryName", FieldCodeInvalid, "Country name does not match countryISO2 " + request.CountryISO2 + " (" + country.Name + ")."})
 	}
 
+	if len(fieldErrors) > 0 {
+		return &ValidationError{fieldErrors}
+	}
 	return nil
 }
 
+// swiftCodeErrorMessage describes a BIC parse error to API clients.
+func swiftCodeErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, bic.ErrInvalidLength):
+		return "Invalid SWIFT code length. Must be 8 or 11 characters."
+	case errors.Is(err, bic.ErrInvalidInstitutionCode):
+		return "Invalid SWIFT code. Characters 1-4 (institution code) must be letters."
+	case errors.Is(err, bic.ErrInvalidCountryCode):
+		return "Invalid SWIFT code. Characters 5-6 (country code) must be letters."
+	case errors.Is(err, bic.ErrInvalidLocationCode):
+		return "Invalid SWIFT code. Characters 7-8 (location code) must be letters or digits."
+	case errors.Is(err, bic.ErrInvalidBranchCode):
+		return "Invalid SWIFT code. Characters 9-11 (branch code) must be letters or digits."
+	}
+	return "Invalid SWIFT code."
+}
+
+// ValidationError lists the invalid fields of a request.
 type ValidationError struct {
-	Message string
+	Errors []FieldError
 }
 
 func (e *ValidationError) Error() string {
-	return e.Message
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fieldError.Message)
+	}
+	return strings.Join(messages, " ")
 }
 
 func createSwiftCodeModel(request *SwiftCodeRequest) models.SwiftCode {
@@ -121,29 +189,25 @@ func createSwiftCodeModel(request *SwiftCodeRequest) models.SwiftCode {
 		BankName:      request.BankName,
 		Address:       request.Address,
 		CountryISO2:   request.CountryISO2,
-		CountryName:   request.CountryName,
+		CountryName:   countries.CanonicalName(request.CountryISO2, request.CountryName),
 		IsHeadquarter: *request.IsHeadquarter,
+		CodeType:      request.CodeType,
+		TownName:      request.TownName,
+		TimeZone:      request.TimeZone,
 	}
 }
 
-func assignHeadquarterID(h *SwiftCodeHandler, newSwiftCode *models.SwiftCode, swiftCode string) error {
+func assignHeadquarterID(repo repositories.SwiftCodeRepositoryInterface, newSwiftCode *models.SwiftCode, swiftCode string) error {
 	if !newSwiftCode.IsHeadquarter {
-		headquarter, err := h.repo.GetBySwiftCode(swiftCode[:8] + "XXX")
+		headquarter, err := repo.GetBySwiftCode(bic.MustParse(swiftCode).PrimaryOffice().String())
 		if err != nil {
 			log.Println("Error finding headquarter:", err)
 			return err
 		}
-		if headquarter != nil {
+		// A record ending in "XXX" must never be linked to itself.
+		if headquarter != nil && headquarter.SwiftCode != swiftCode {
 			newSwiftCode.HeadquarterID = &headquarter.ID
 		}
 	}
 	return nil
 }
-
-func respondWithError(c *gin.Context, statusCode int, message string, details ...string) {
-	errorResponse := gin.H{"error": message}
-	if len(details) > 0 {
-		errorResponse["details"] = details[0]
-	}
-	c.JSON(statusCode, errorResponse)
-}
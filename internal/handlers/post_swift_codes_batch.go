@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+)
+
+// maxBatchSize limits the number of SWIFT codes created by a single batch request.
+const maxBatchSize = 1000
+
+// Per-item batch result statuses.
+const (
+	BatchStatusCreated   = "CREATED"
+	BatchStatusInvalid   = "INVALID"
+	BatchStatusDuplicate = "DUPLICATE"
+)
+
+// BatchRequest is the body of POST /v1/swift-codes/batch.
+// Items are decoded one by one so that a malformed item is reported instead of failing the request.
+type BatchRequest struct {
+	SwiftCodes   []json.RawMessage `json:"swiftCodes" binding:"required"`
+	AllOrNothing bool              `json:"allOrNothing"`
+}
+
+// BatchItemResult reports the outcome for one item of a batch, identified by its position.
+type BatchItemResult struct {
+	Index     int          `json:"index"`
+	SwiftCode string       `json:"swiftCode,omitempty"`
+	Status    string       `json:"status"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// AddSwiftCodesBatch handles POST /v1/swift-codes/batch requests.
+// Every item is validated like a single POST and the valid ones are inserted in one
+// transaction, in request order. Branches are linked to headquarters from the same batch
+// whichever comes first. With allOrNothing, any failing item rejects the whole batch.
+func (h *SwiftCodeHandler) AddSwiftCodesBatch(c *gin.Context) {
+	var request BatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondWithBindingError(c, err)
+		return
+	}
+
+	if len(request.SwiftCodes) == 0 || len(request.SwiftCodes) > maxBatchSize {
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"swiftCodes", FieldCodeInvalid, "A batch must contain between 1 and " + strconv.Itoa(maxBatchSize) + " SWIFT codes."})
+		return
+	}
+
+	results := make([]BatchItemResult, len(request.SwiftCodes))
+	items := make([]*SwiftCodeRequest, len(request.SwiftCodes))
+	for i, raw := range request.SwiftCodes {
+		item, fieldErrors := decodeBatchItem(raw)
+		results[i] = BatchItemResult{Index: i, SwiftCode: item.SwiftCode}
+		if len(fieldErrors) > 0 {
+			results[i].Status, results[i].Errors = BatchStatusInvalid, fieldErrors
+			continue
+		}
+		items[i] = item
+	}
+
+	if request.AllOrNothing && hasFailedItems(results) {
+		respondWithBatchRejection(c, results)
+		return
+	}
+
+	err := h.repo.WithTx(c.Request.Context(), func(repo repositories.SwiftCodeRepositoryInterface) error {
+		for i, item := range items {
+			if item == nil {
+				continue
+			}
+
+			err := insertSwiftCodeRequest(repo, item)
+			var txErr *txError
+			if errors.As(err, &txErr) && txErr.code == CodeDuplicateSwiftCode {
+				results[i].Status = BatchStatusDuplicate
+				results[i].Errors = []FieldError{{"swiftCode", FieldCodeDuplicate, "SWIFT code already exists in the database"}}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			results[i].Status = BatchStatusCreated
+		}
+
+		if request.AllOrNothing && hasFailedItems(results) {
+			return &txError{http.StatusUnprocessableEntity, CodeBatchRejected, "", nil}
+		}
+		return nil
+	})
+
+	var txErr *txError
+	if errors.As(err, &txErr) && txErr.code == CodeBatchRejected {
+		respondWithBatchRejection(c, results)
+		return
+	}
+	if err != nil {
+		respondWithTxError(c, err)
+		return
+	}
+
+	created := countCreated(results)
+	c.JSON(http.StatusOK, gin.H{
+		"created": created,
+		"failed":  len(results) - created,
+		"results": results,
+	})
+}
+
+// decodeBatchItem decodes, normalizes and validates a single batch item.
+func decodeBatchItem(raw json.RawMessage) (*SwiftCodeRequest, []FieldError) {
+	var item SwiftCodeRequest
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return &item, []FieldError{{"", FieldCodeInvalid, "Item is not a valid SWIFT code object: " + err.Error()}}
+	}
+
+	if err := binding.Validator.ValidateStruct(&item); err != nil {
+		fieldErrors, ok := bindingFieldErrors(err)
+		if !ok {
+			fieldErrors = []FieldError{{"", FieldCodeInvalid, err.Error()}}
+		}
+		return &item, fieldErrors
+	}
+
+	normalizeSwiftCodeRequest(&item)
+	if err := validateSwiftCodeRequest(&item); err != nil {
+		return &item, err.Errors
+	}
+	return &item, nil
+}
+
+// respondWithBatchRejection reports every failing item of an all-or-nothing batch.
+// Field names are prefixed with the item position, e.g. "swiftCodes[3].bankName".
+func respondWithBatchRejection(c *gin.Context, results []BatchItemResult) {
+	var fieldErrors []FieldError
+	failed := 0
+	for _, result := range results {
+		if len(result.Errors) == 0 {
+			continue
+		}
+		failed++
+		for _, fieldError := range result.Errors {
+			field := fmt.Sprintf("swiftCodes[%d]", result.Index)
+			if fieldError.Field != "" {
+				field += "." + fieldError.Field
+			}
+			fieldErrors = append(fieldErrors, FieldError{field, fieldError.Code, fieldError.Message})
+		}
+	}
+
+	detail := fmt.Sprintf("No SWIFT codes were saved: %d of %d items failed", failed, len(results))
+	respondWithError(c, http.StatusUnprocessableEntity, CodeBatchRejected, detail, fieldErrors...)
+}
+
+func hasFailedItems(results []BatchItemResult) bool {
+	for _, result := range results {
+		if len(result.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func countCreated(results []BatchItemResult) int {
+	created := 0
+	for _, result := range results {
+		if result.Status == BatchStatusCreated {
+			created++
+		}
+	}
+	return created
+}
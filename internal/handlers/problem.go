@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mroczekDNF/swift-api/internal/middleware"
+)
+
+// problemContentType is the media type of error responses (RFC 7807).
+const problemContentType = "application/problem+json"
+
+// Stable, machine-readable error codes returned in the "code" member of every error response.
+const (
+	CodeInvalidRequest           = "INVALID_REQUEST"
+	CodeValidationFailed         = "VALIDATION_FAILED"
+	CodeInvalidQuery             = "INVALID_QUERY_PARAMETER"
+	CodeSwiftNotFound            = "SWIFT_NOT_FOUND"
+	CodeCountryNotFound          = "COUNTRY_NOT_FOUND"
+	CodeDuplicateSwiftCode       = "DUPLICATE_SWIFT_CODE"
+	CodeSwiftCodeImmutable       = "SWIFT_CODE_IMMUTABLE"
+	CodePreconditionRequired     = "PRECONDITION_REQUIRED"
+	CodePreconditionFailed       = "PRECONDITION_FAILED"
+	CodeBatchRejected            = "BATCH_REJECTED"
+	CodeInvalidImportFile        = "INVALID_IMPORT_FILE"
+	CodeImportFileTooLarge       = "IMPORT_FILE_TOO_LARGE"
+	CodeRequestTooLarge          = "REQUEST_TOO_LARGE"
+	CodeInvalidIdempotencyKey    = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused     = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeRouteNotFound            = "ROUTE_NOT_FOUND"
+	CodeInternalError            = "INTERNAL_ERROR"
+)
+
+// Per-field validation error codes.
+const (
+	FieldCodeRequired  = "REQUIRED"
+	FieldCodeInvalid   = "INVALID"
+	FieldCodeDuplicate = "DUPLICATE"
+)
+
+// Problem is the error response body, following RFC 7807 problem details.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes a single invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// respondWithError writes a problem+json response and aborts the request.
+func respondWithError(c *gin.Context, statusCode int, code, detail string, fieldErrors ...FieldError) {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetString(middleware.RequestIDKey),
+		Errors:    fieldErrors,
+	}
+
+	body, err := json.Marshal(problem)
+	if err != nil {
+		log.Println("Error encoding problem response:", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(statusCode, problemContentType, body)
+	c.Abort()
+}
+
+// respondWithBindingError writes the response for a request body that could not be bound.
+// Validator failures are reported per field, anything else as a malformed body.
+func respondWithBindingError(c *gin.Context, err error) {
+	fieldErrors, ok := bindingFieldErrors(err)
+	if !ok {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request structure: "+err.Error())
+		return
+	}
+	respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed", fieldErrors...)
+}
+
+// bindingFieldErrors converts validator failures to field errors.
+// It reports false when err is not a validation error, e.g. malformed JSON.
+func bindingFieldErrors(err error) ([]FieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		field := jsonFieldName(fe.Field())
+		if fe.Tag() == "required" {
+			fieldErrors = append(fieldErrors, FieldError{field, FieldCodeRequired, "Field '" + field + "' is required"})
+			continue
+		}
+		fieldErrors = append(fieldErrors, FieldError{field, FieldCodeInvalid, "Field '" + field + "' is invalid"})
+	}
+	return fieldErrors, true
+}
+
+// jsonFieldName converts a request struct field name to its JSON name.
+func jsonFieldName(field string) string {
+	if field == "" {
+		return field
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+// NoRoute responds to requests for unknown endpoints.
+func NoRoute(c *gin.Context) {
+	respondWithError(c, http.StatusNotFound, CodeRouteNotFound, "No endpoint matches "+c.Request.Method+" "+c.Request.URL.Path)
+}
+
+// Recovery responds to requests whose handler panicked.
+func Recovery(c *gin.Context, recovered any) {
+	log.Printf("Recovered from panic: %v", recovered)
+	respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Unexpected server error")
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/countries"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	minSearchLength    = 2
+)
+
+// SearchSwiftCodes handles GET /v1/swift-codes/search?q= requests.
+func (h *SwiftCodeHandler) SearchSwiftCodes(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if utf8.RuneCountInString(query) < minSearchLength {
+		respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, "Query parameter 'q' must contain at least 2 characters.")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			respondWithError(c, http.StatusBadRequest, CodeInvalidQuery, "Invalid 'limit' value. Must be between 1 and 100.")
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.repo.SearchSwiftCodes(query, limit)
+	if err != nil {
+		log.Println("Error searching SWIFT codes:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error searching SWIFT codes")
+		return
+	}
+
+	formattedResults := make([]gin.H, 0, len(results))
+	for _, result := range results {
+		formattedResults = append(formattedResults, gin.H{
+			"address":       result.Record.Address,
+			"bankName":      result.Record.BankName,
+			"codeType":      result.Record.CodeType,
+			"countryISO2":   result.Record.CountryISO2,
+			"countryName":   countries.CanonicalName(result.Record.CountryISO2, result.Record.CountryName),
+			"isHeadquarter": result.Record.IsHeadquarter,
+			"swiftCode":     result.Record.SwiftCode,
+			"timeZone":      result.Record.TimeZone,
+			"townName":      result.Record.TownName,
+			"score":         result.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"results": formattedResults,
+	})
+}
@@ -1,6 +1,13 @@
 package handlers
 
-import "github.com/mroczekDNF/swift-api/internal/repositories"
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+)
 
 // SwiftCodeHandler handles operations on SWIFT codes.
 type SwiftCodeHandler struct {
@@ -11,3 +18,43 @@ type SwiftCodeHandler struct {
 func NewSwiftCodeHandler(repo repositories.SwiftCodeRepositoryInterface) *SwiftCodeHandler {
 	return &SwiftCodeHandler{repo: repo}
 }
+
+// txError aborts a repository transaction and carries the response to send.
+type txError struct {
+	status  int
+	code    string
+	message string
+	err     error
+}
+
+func (e *txError) Error() string {
+	if e.err != nil {
+		return e.message + ": " + e.err.Error()
+	}
+	return e.message
+}
+
+func (e *txError) Unwrap() error {
+	return e.err
+}
+
+// respondWithTxError writes the response for an error returned by WithTx.
+func respondWithTxError(c *gin.Context, err error) {
+	var txErr *txError
+	if !errors.As(err, &txErr) {
+		log.Println("Error committing transaction:", err)
+		respondWithError(c, http.StatusInternalServerError, CodeInternalError, "Error saving changes")
+		return
+	}
+
+	var validationErr *ValidationError
+	if errors.As(txErr.err, &validationErr) {
+		respondWithError(c, txErr.status, txErr.code, txErr.message, validationErr.Errors...)
+		return
+	}
+	if txErr.status == http.StatusBadRequest && txErr.err != nil {
+		respondWithError(c, txErr.status, txErr.code, txErr.message+": "+txErr.err.Error())
+		return
+	}
+	respondWithError(c, txErr.status, txErr.code, txErr.message)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
+)
+
+// UpdateSwiftCode handles PUT /v1/swift-codes/{swiftCode} requests (full replace).
+// A BIC8 addresses its <BIC8>XXX record.
+func (h *SwiftCodeHandler) UpdateSwiftCode(c *gin.Context) {
+	swiftCode := bic.Canonical(c.Param("swiftCode"))
+
+	var request SwiftCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondWithBindingError(c, err)
+		return
+	}
+
+	if request.IsHeadquarter == nil {
+		respondWithError(c, http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+			FieldError{"isHeadquarter", FieldCodeRequired, "Field 'isHeadquarter' is required"})
+		return
+	}
+
+	h.updateSwiftCode(c, swiftCode, func(existing *models.SwiftCode) (*SwiftCodeRequest, error) {
+		return &request, nil
+	})
+}
+
+// PatchSwiftCode handles PATCH /v1/swift-codes/{swiftCode} requests.
+// The body is applied to the stored record with JSON merge patch semantics:
+// present fields replace the stored value and null removes it. A BIC8 addresses its <BIC8>XXX record.
+func (h *SwiftCodeHandler) PatchSwiftCode(c *gin.Context) {
+	swiftCode := bic.Canonical(c.Param("swiftCode"))
+
+	var patch map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		respondWithBindingError(c, err)
+		return
+	}
+
+	h.updateSwiftCode(c, swiftCode, func(existing *models.SwiftCode) (*SwiftCodeRequest, error) {
+		request, err := mergePatchSwiftCodeRequest(createSwiftCodeRequest(existing), patch)
+		if err != nil {
+			return nil, &txError{http.StatusBadRequest, CodeInvalidRequest, "Invalid request structure", err}
+		}
+		if request.IsHeadquarter == nil {
+			return nil, &txError{http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+				&ValidationError{[]FieldError{{"isHeadquarter", FieldCodeRequired, "Field 'isHeadquarter' is required"}}}}
+		}
+		return &request, nil
+	})
+}
+
+// updateSwiftCode loads the record addressed by the path, builds the requested
+// state from it and stores the result in a single transaction. The If-Match header
+// must hold the current ETag of the record, see checkIfMatch; the response carries
+// the ETag of the updated record.
+func (h *SwiftCodeHandler) updateSwiftCode(c *gin.Context, swiftCode string, buildRequest func(existing *models.SwiftCode) (*SwiftCodeRequest, error)) {
+	var etag string
+	err := h.repo.WithTx(c.Request.Context(), func(repo repositories.SwiftCodeRepositoryInterface) error {
+		existing, err := repo.GetBySwiftCode(swiftCode)
+		if err != nil {
+			log.Println("Error retrieving SWIFT code:", err)
+			return &txError{http.StatusInternalServerError, CodeInternalError, "Error retrieving SWIFT code", err}
+		}
+		if existing == nil {
+			return &txError{http.StatusNotFound, CodeSwiftNotFound, "SWIFT code not found", nil}
+		}
+		if err := checkIfMatch(c, repo, existing); err != nil {
+			return err
+		}
+
+		request, err := buildRequest(existing)
+		if err != nil {
+			return err
+		}
+		if err := applySwiftCodeUpdate(repo, existing, request); err != nil {
+			return err
+		}
+
+		// The database moved the version forward; read it back for the new ETag.
+		updated, err := repo.GetBySwiftCode(swiftCode)
+		if err != nil || updated == nil {
+			log.Println("Error retrieving updated SWIFT code:", err)
+			return &txError{http.StatusInternalServerError, CodeInternalError, "Error retrieving SWIFT code", err}
+		}
+		etag, err = currentETag(repo, updated)
+		return err
+	})
+	if err != nil {
+		respondWithTxError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.JSON(http.StatusOK, gin.H{"message": "SWIFT code updated successfully"})
+}
+
+// applySwiftCodeUpdate validates the requested state and stores the result. The SWIFT code
+// cannot change and isHeadquarter must match it, so headquarter links stay as they are.
+func applySwiftCodeUpdate(repo repositories.SwiftCodeRepositoryInterface, existing *models.SwiftCode, request *SwiftCodeRequest) error {
+	normalizeSwiftCodeRequest(request)
+
+	if request.SwiftCode != existing.SwiftCode {
+		return &txError{http.StatusBadRequest, CodeSwiftCodeImmutable, "SWIFT code cannot be changed", nil}
+	}
+
+	if err := validateSwiftCodeRequest(request); err != nil {
+		return &txError{http.StatusBadRequest, CodeValidationFailed, "Request validation failed", err}
+	}
+
+	updated := createSwiftCodeModel(request)
+	updated.ID = existing.ID
+	updated.HeadquarterID = existing.HeadquarterID
+	updated.Version = existing.Version
+
+	if err := repo.UpdateSwiftCode(&updated); errors.Is(err, repositories.ErrVersionConflict) {
+		return &txError{http.StatusPreconditionFailed, CodePreconditionFailed, "The SWIFT code was changed while it was being updated", err}
+	} else if err != nil {
+		log.Println("Error updating SWIFT code:", err)
+		return &txError{http.StatusInternalServerError, CodeInternalError, "Error updating SWIFT code", err}
+	}
+	return nil
+}
+
+func createSwiftCodeRequest(swift *models.SwiftCode) SwiftCodeRequest {
+	isHeadquarter := swift.IsHeadquarter
+	return SwiftCodeRequest{
+		Address:       swift.Address,
+		BankName:      swift.BankName,
+		CodeType:      swift.CodeType,
+		CountryISO2:   swift.CountryISO2,
+		CountryName:   swift.CountryName,
+		IsHeadquarter: &isHeadquarter,
+		SwiftCode:     swift.SwiftCode,
+		TimeZone:      swift.TimeZone,
+		TownName:      swift.TownName,
+	}
+}
+
+// mergePatchSwiftCodeRequest applies an RFC 7396 merge patch to the given request.
+func mergePatchSwiftCodeRequest(target SwiftCodeRequest, patch map[string]json.RawMessage) (SwiftCodeRequest, error) {
+	document, err := json.Marshal(target)
+	if err != nil {
+		return SwiftCodeRequest{}, err
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(document, &merged); err != nil {
+		return SwiftCodeRequest{}, err
+	}
+
+	for key, value := range patch {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+
+	document, err = json.Marshal(merged)
+	if err != nil {
+		return SwiftCodeRequest{}, err
+	}
+
+	var result SwiftCodeRequest
+	if err := json.Unmarshal(document, &result); err != nil {
+		return SwiftCodeRequest{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader carries the request ID in both directions.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key holding the request ID.
+	RequestIDKey = "requestID"
+)
+
+// requestIDRegex limits client supplied IDs to a safe, log friendly format.
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID assigns every request an ID, reusing a well-formed X-Request-ID header
+// sent by the client, and echoes it in the response headers.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// newRequestID returns a random 128-bit hex encoded ID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
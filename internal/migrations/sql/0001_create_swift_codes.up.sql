@@ -1,6 +1,4 @@
-DROP TABLE IF EXISTS swift_codes;
-
-CREATE TABLE swift_codes (
+CREATE TABLE IF NOT EXISTS swift_codes (
     id SERIAL PRIMARY KEY,
     swift_code VARCHAR(11) UNIQUE NOT NULL,
     bank_name TEXT NOT NULL,
@@ -11,5 +9,5 @@ CREATE TABLE swift_codes (
     headquarter_id INT
 );
 
--- Dodanie indeksu na headquarter_id dla szybkiego wyszukiwania branchy
+-- Index on headquarter_id for faster branch lookups
 CREATE INDEX IF NOT EXISTS idx_headquarter_id ON swift_codes (headquarter_id);
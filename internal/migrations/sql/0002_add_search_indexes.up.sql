@@ -0,0 +1,6 @@
+-- Full-text and trigram indexes for bank name search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_swift_codes_search ON swift_codes
+    USING GIN (to_tsvector('simple', bank_name || ' ' || COALESCE(address, '')));
+CREATE INDEX IF NOT EXISTS idx_swift_codes_bank_name_trgm ON swift_codes USING GIN (bank_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_swift_codes_address_trgm ON swift_codes USING GIN (address gin_trgm_ops);
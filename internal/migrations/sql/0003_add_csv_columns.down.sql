@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_swift_codes_town_name_trgm;
+DROP INDEX IF EXISTS idx_swift_codes_document;
+CREATE INDEX IF NOT EXISTS idx_swift_codes_search ON swift_codes
+    USING GIN (to_tsvector('simple', bank_name || ' ' || COALESCE(address, '')));
+
+ALTER TABLE swift_codes DROP COLUMN IF EXISTS time_zone;
+ALTER TABLE swift_codes DROP COLUMN IF EXISTS town_name;
+ALTER TABLE swift_codes DROP COLUMN IF EXISTS code_type;
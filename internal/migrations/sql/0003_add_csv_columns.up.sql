@@ -0,0 +1,10 @@
+-- CODE TYPE, TOWN NAME and TIME ZONE columns of the source CSV
+ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS code_type TEXT;
+ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS town_name TEXT;
+ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS time_zone TEXT;
+
+-- Search covers the town name as well
+DROP INDEX IF EXISTS idx_swift_codes_search;
+CREATE INDEX IF NOT EXISTS idx_swift_codes_document ON swift_codes
+    USING GIN (to_tsvector('simple', bank_name || ' ' || COALESCE(address, '') || ' ' || COALESCE(town_name, '')));
+CREATE INDEX IF NOT EXISTS idx_swift_codes_town_name_trgm ON swift_codes USING GIN (town_name gin_trgm_ops);
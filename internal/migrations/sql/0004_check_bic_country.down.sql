@@ -0,0 +1 @@
+ALTER TABLE swift_codes DROP CONSTRAINT IF EXISTS swift_codes_bic_country_check;
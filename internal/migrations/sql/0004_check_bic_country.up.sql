@@ -0,0 +1,6 @@
+-- Characters 5-6 of a SWIFT code are the ISO 3166 country code of the institution.
+-- NOT VALID keeps existing inconsistent rows loadable; new and updated rows are checked.
+-- Run `ALTER TABLE swift_codes VALIDATE CONSTRAINT swift_codes_bic_country_check;` once old rows are fixed.
+ALTER TABLE swift_codes
+    ADD CONSTRAINT swift_codes_bic_country_check
+    CHECK (SUBSTRING(swift_code FROM 5 FOR 2) = country_iso2) NOT VALID;
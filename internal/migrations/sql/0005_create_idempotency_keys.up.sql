@@ -0,0 +1,13 @@
+-- Responses to mutating requests sent with an Idempotency-Key header, replayed to retries.
+-- status_code is NULL while the first request with the key is being processed.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key TEXT PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT,
+    content_type TEXT,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Index on created_at for purging expired keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
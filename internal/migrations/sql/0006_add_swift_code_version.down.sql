@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS swift_codes_version ON swift_codes;
+DROP FUNCTION IF EXISTS swift_codes_bump_version();
+ALTER TABLE swift_codes DROP COLUMN IF EXISTS version;
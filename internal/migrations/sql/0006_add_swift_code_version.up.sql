@@ -0,0 +1,15 @@
+-- Row version for optimistic concurrency, exposed as the ETag of a SWIFT code
+ALTER TABLE swift_codes ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Every change to a row moves its version forward, whichever statement makes it
+-- (API updates, imports, sync mode or headquarter relinking).
+CREATE OR REPLACE FUNCTION swift_codes_bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS swift_codes_version ON swift_codes;
+CREATE TRIGGER swift_codes_version BEFORE UPDATE ON swift_codes
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION swift_codes_bump_version();
@@ -0,0 +1,2 @@
+-- Which codes were stored in the 8-character form is not recorded; they keep the 11-character form.
+SELECT 1;
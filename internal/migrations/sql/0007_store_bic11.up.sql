@@ -0,0 +1,15 @@
+-- An 8-character SWIFT code names the primary office and is stored in its 11-character form,
+-- so that it cannot exist twice and its branches link to it by prefix. Such codes used to be
+-- stored as branches, so they become headquarters as well.
+-- Codes whose 11-character form is stored as well are left for manual cleanup.
+UPDATE swift_codes SET swift_code = swift_code || 'XXX', is_headquarter = true, headquarter_id = NULL
+WHERE char_length(swift_code) = 8
+    AND NOT EXISTS (SELECT 1 FROM swift_codes AS other WHERE other.swift_code = swift_codes.swift_code || 'XXX');
+
+UPDATE swift_codes AS branch
+SET headquarter_id = hq.id
+FROM swift_codes AS hq
+WHERE branch.is_headquarter = false
+    AND hq.is_headquarter = true
+    AND hq.swift_code = LEFT(branch.swift_code, 8) || 'XXX'
+    AND branch.headquarter_id IS DISTINCT FROM hq.id;
@@ -0,0 +1,2 @@
+-- ETag header of a stored response, replayed together with its body
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS etag TEXT;
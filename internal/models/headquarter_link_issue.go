@@ -0,0 +1,18 @@
+package models
+
+// Rodzaje problemów z powiązaniem oddziału z siedzibą główną
+const (
+	LinkIssueMissing          = "MISSING_LINK"      // Siedziba istnieje, ale oddział nie jest z nią powiązany
+	LinkIssueDangling         = "DANGLING_LINK"     // headquarter_id wskazuje na nieistniejący rekord
+	LinkIssueWrongInstitution = "WRONG_INSTITUTION" // headquarter_id wskazuje na inną instytucję (inny prefiks BIC8)
+	LinkIssueNotHeadquarter   = "NOT_A_HEADQUARTER" // headquarter_id wskazuje na rekord, który nie jest siedzibą
+)
+
+// HeadquarterLinkIssue opisuje oddział z błędnym powiązaniem headquarter_id
+type HeadquarterLinkIssue struct {
+	SwiftCode           string  // Kod SWIFT oddziału
+	HeadquarterID       *int64  // Aktualna wartość headquarter_id
+	LinkedSwiftCode     *string // Kod SWIFT rekordu wskazywanego przez headquarter_id
+	ExpectedHeadquarter *string // Kod SWIFT siedziby o tym samym prefiksie BIC8
+	Problem             string  // Rodzaj problemu (LinkIssue*)
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdempotencyKey przechowuje odpowiedź na żądanie wysłane z nagłówkiem Idempotency-Key
+type IdempotencyKey struct {
+	Key          string    // Wartość nagłówka Idempotency-Key
+	RequestHash  string    // Skrót SHA-256 metody, ścieżki i treści żądania
+	StatusCode   *int      // Status odpowiedzi (nil, dopóki pierwsze żądanie jest przetwarzane)
+	ContentType  string    // Typ treści odpowiedzi
+	ETag         string    // Nagłówek ETag odpowiedzi, jeśli był ustawiony
+	ResponseBody []byte    // Treść odpowiedzi
+	CreatedAt    time.Time // Czas przyjęcia pierwszego żądania
+}
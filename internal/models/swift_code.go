@@ -11,4 +11,8 @@ type SwiftCode struct {
 	CountryName   string // Nazwa kraju (niepusty)
 	IsHeadquarter bool   // Czy to siedziba główna
 	HeadquarterID *int64 // ID siedziby głównej (dla oddziałów)
+	CodeType      string // Typ kodu (np. BIC11)
+	TownName      string // Nazwa miejscowości
+	TimeZone      string // Strefa czasowa oddziału (np. Europe/Warsaw)
+	Version       int64  // Wersja rekordu, zwiększana przy każdej zmianie (podstawa nagłówka ETag)
 }
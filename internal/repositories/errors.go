@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicateSwiftCode is returned when a SWIFT code being inserted already exists,
+// including when a concurrent transaction inserted it first.
+var ErrDuplicateSwiftCode = errors.New("SWIFT code already exists")
+
+// ErrVersionConflict is returned when a SWIFT code being updated or deleted no longer has
+// the version it was read with, because another transaction changed or removed it since.
+var ErrVersionConflict = errors.New("SWIFT code was changed concurrently")
+
+// uniqueViolation is the PostgreSQL SQLSTATE of a unique constraint violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
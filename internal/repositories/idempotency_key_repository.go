@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+)
+
+// abandonedReservationAge is how long a key stays reserved for a request that never
+// completed, e.g. because the server stopped while processing it.
+const abandonedReservationAge = 10 * time.Minute
+
+// IdempotencyKeyRepositoryInterface defines idempotency key storage methods
+type IdempotencyKeyRepositoryInterface interface {
+	ReserveKey(key, requestHash string, window time.Duration) (*models.IdempotencyKey, error)
+	SaveResponse(key string, statusCode int, contentType, etag string, body []byte) error
+	ReleaseKey(key string) error
+	DeleteExpiredKeys(window time.Duration) (int64, error)
+}
+
+// IdempotencyKeyRepository handles operations on the idempotency_keys table
+type IdempotencyKeyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyKeyRepository creates a new idempotency key repository instance
+func NewIdempotencyKeyRepository(db *sql.DB) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{db: db}
+}
+
+// ReserveKey reserves key for a request with the given hash. It returns nil when the key
+// was free, and the stored key otherwise: with the response of an earlier request, or
+// without one while that request is still being processed. Keys older than window are
+// treated as free.
+func (r *IdempotencyKeyRepository) ReserveKey(key, requestHash string, window time.Duration) (*models.IdempotencyKey, error) {
+	// A concurrent release may remove the key between the insert and the select; try again then.
+	for attempt := 0; attempt < 3; attempt++ {
+		_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE key = $1
+			AND (created_at < now() - make_interval(secs => $2)
+				OR (status_code IS NULL AND created_at < now() - make_interval(secs => $3)));`,
+			key, window.Seconds(), abandonedReservationAge.Seconds())
+		if err != nil {
+			log.Println("Error removing expired idempotency key in ReserveKey:", err)
+			return nil, err
+		}
+
+		result, err := r.db.Exec("INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING;",
+			key, requestHash)
+		if err != nil {
+			log.Println("Error reserving idempotency key in ReserveKey:", err)
+			return nil, err
+		}
+		if inserted, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if inserted == 1 {
+			return nil, nil
+		}
+
+		stored := &models.IdempotencyKey{}
+		var statusCode sql.NullInt64
+		var contentType, etag sql.NullString
+		err = r.db.QueryRow("SELECT key, request_hash, status_code, content_type, etag, response_body, created_at FROM idempotency_keys WHERE key = $1;", key).
+			Scan(&stored.Key, &stored.RequestHash, &statusCode, &contentType, &etag, &stored.ResponseBody, &stored.CreatedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			log.Println("Database query error in ReserveKey:", err)
+			return nil, err
+		}
+
+		if statusCode.Valid {
+			status := int(statusCode.Int64)
+			stored.StatusCode = &status
+		}
+		stored.ContentType = contentType.String
+		stored.ETag = etag.String
+		return stored, nil
+	}
+	return nil, fmt.Errorf("idempotency key %q is being reserved and released concurrently", key)
+}
+
+// SaveResponse stores the response to the request that reserved key; etag may be empty
+func (r *IdempotencyKeyRepository) SaveResponse(key string, statusCode int, contentType, etag string, body []byte) error {
+	_, err := r.db.Exec("UPDATE idempotency_keys SET status_code = $2, content_type = $3, etag = NULLIF($4, ''), response_body = $5 WHERE key = $1;",
+		key, statusCode, contentType, etag, body)
+	if err != nil {
+		log.Println("Error saving response in SaveResponse:", err)
+	}
+	return err
+}
+
+// ReleaseKey removes a reservation, so that the request can be retried
+func (r *IdempotencyKeyRepository) ReleaseKey(key string) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE key = $1;", key)
+	if err != nil {
+		log.Println("Error releasing idempotency key in ReleaseKey:", err)
+	}
+	return err
+}
+
+// DeleteExpiredKeys removes keys older than window and returns how many were removed
+func (r *IdempotencyKeyRepository) DeleteExpiredKeys(window time.Duration) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1);", window.Seconds())
+	if err != nil {
+		log.Println("Error deleting expired idempotency keys in DeleteExpiredKeys:", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import "github.com/mroczekDNF/swift-api/internal/models"
+
+// SortColumns maps the sort fields accepted by ListSwiftCodes to database columns
+var SortColumns = map[string]string{
+	"swiftCode":   "swift_code",
+	"bankName":    "bank_name",
+	"countryISO2": "country_iso2",
+}
+
+// SwiftCodeFilter narrows, orders and pages the results of ListSwiftCodes
+type SwiftCodeFilter struct {
+	CountryISO2   string           // Exact country code
+	BankName      string           // Case-insensitive substring of the bank name
+	Town          string           // Case-insensitive substring of the town
+	IsHeadquarter *bool            // Only headquarters or only branches
+	CodePrefix    string           // Leading characters of the SWIFT code
+	SortBy        string           // Key of SortColumns
+	Descending    bool             // Reverse the sort order
+	After         *SwiftCodeCursor // Position of the last row of the previous page
+	Limit         int              // Maximum number of rows to return
+}
+
+// SwiftCodeCursor identifies the last row of a page: its sort value and its unique SWIFT code
+type SwiftCodeCursor struct {
+	SortValue string
+	SwiftCode string
+}
+
+// SwiftCodeSearchResult is a SWIFT code matched by SearchSwiftCodes together with its relevance
+type SwiftCodeSearchResult struct {
+	Record models.SwiftCode
+	Score  float64
+}
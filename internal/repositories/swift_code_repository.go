@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/mroczekDNF/swift-api/internal/models"
 )
@@ -10,34 +13,87 @@ import (
 // SwiftCodeRepositoryInterface defines repository methods
 type SwiftCodeRepositoryInterface interface {
 	GetBySwiftCode(code string) (*models.SwiftCode, error)
+	GetBySwiftCodes(codes []string) ([]models.SwiftCode, error)
 	GetByCountryISO2(countryISO2 string) ([]models.SwiftCode, error)
-	DeleteSwiftCode(code string) error
+	ListSwiftCodes(filter SwiftCodeFilter) ([]models.SwiftCode, error)
+	SearchSwiftCodes(query string, limit int) ([]SwiftCodeSearchResult, error)
+	DeleteSwiftCode(code string, version int64) error
 	DetachBranchesFromHeadquarter(headquarterID int64) error
 	InsertSwiftCode(swift *models.SwiftCode) error
+	UpdateSwiftCode(swift *models.SwiftCode) error
 	GetBranchesByHeadquarter(headquarterCode string) ([]models.SwiftCode, error)
 	AssignBranchesToHeadquarter(headquarterCode string) error
+	FindHeadquarterLinkIssues() ([]models.HeadquarterLinkIssue, error)
+	WithTx(ctx context.Context, fn func(repo SwiftCodeRepositoryInterface) error) error
+}
+
+// swiftCodeColumns lists the columns read by scanSwiftCode, in scan order
+const swiftCodeColumns = "id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone, version"
+
+// dbExecutor is the subset of *sql.DB and *sql.Tx used by the repository
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
 // SwiftCodeRepository handles operations on the swift_codes table
 type SwiftCodeRepository struct {
-	db *sql.DB
+	db   dbExecutor
+	conn *sql.DB
 }
 
 // NewSwiftCodeRepository creates a new SwiftCode repository instance
 func NewSwiftCodeRepository(db *sql.DB) *SwiftCodeRepository {
-	return &SwiftCodeRepository{db: db}
+	return &SwiftCodeRepository{db: db, conn: db}
 }
 
-// scanSwiftCode processes the SQL query result and populates a models.SwiftCode object
+// WithTx runs fn as a single unit of work. The repository passed to fn executes
+// every statement in one transaction, which is committed when fn returns nil and
+// rolled back otherwise. Calls nested inside fn reuse the outer transaction.
+func (r *SwiftCodeRepository) WithTx(ctx context.Context, fn func(repo SwiftCodeRepositoryInterface) error) (err error) {
+	if _, inTx := r.db.(*sql.Tx); inTx {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println("Error starting transaction in WithTx:", err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(&SwiftCodeRepository{db: tx, conn: r.conn}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Println("Error rolling back transaction in WithTx:", rbErr)
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction in WithTx:", err)
+	}
+	return err
+}
+
+// scanSwiftCode processes the SQL query result and populates a models.SwiftCode object.
+// Columns selected after swiftCodeColumns are scanned into extra.
 func scanSwiftCode(scanner interface {
 	Scan(dest ...interface{}) error
-}) (*models.SwiftCode, error) {
+}, extra ...interface{}) (*models.SwiftCode, error) {
 	swift := &models.SwiftCode{}
-	var address sql.NullString
+	var address, codeType, townName, timeZone sql.NullString
 
-	err := scanner.Scan(&swift.ID, &swift.SwiftCode, &swift.BankName, &address,
-		&swift.CountryISO2, &swift.CountryName, &swift.IsHeadquarter, &swift.HeadquarterID)
-	if err != nil {
+	dest := []interface{}{&swift.ID, &swift.SwiftCode, &swift.BankName, &address,
+		&swift.CountryISO2, &swift.CountryName, &swift.IsHeadquarter, &swift.HeadquarterID,
+		&codeType, &townName, &timeZone, &swift.Version}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
 
@@ -45,12 +101,15 @@ func scanSwiftCode(scanner interface {
 	if address.Valid {
 		swift.Address = address.String
 	}
+	swift.CodeType = codeType.String
+	swift.TownName = townName.String
+	swift.TimeZone = timeZone.String
 	return swift, nil
 }
 
 // GetBySwiftCode retrieves a SWIFT code by its value
 func (r *SwiftCodeRepository) GetBySwiftCode(code string) (*models.SwiftCode, error) {
-	query := "SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id FROM swift_codes WHERE swift_code = $1;"
+	query := "SELECT " + swiftCodeColumns + " FROM swift_codes WHERE swift_code = $1;"
 
 	swift, err := scanSwiftCode(r.db.QueryRow(query, code))
 	if err != nil {
@@ -62,9 +121,32 @@ func (r *SwiftCodeRepository) GetBySwiftCode(code string) (*models.SwiftCode, er
 	return swift, err
 }
 
+// GetBySwiftCodes retrieves the SWIFT codes matching any of the given values in a single query.
+// Codes that do not exist are left out; the order of the result is unspecified.
+func (r *SwiftCodeRepository) GetBySwiftCodes(codes []string) ([]models.SwiftCode, error) {
+	query := "SELECT " + swiftCodeColumns + " FROM swift_codes WHERE swift_code = ANY($1);"
+
+	rows, err := r.db.Query(query, codes)
+	if err != nil {
+		log.Println("Database query error in GetBySwiftCodes:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	swiftCodes := make([]models.SwiftCode, 0, len(codes))
+	for rows.Next() {
+		swift, err := scanSwiftCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		swiftCodes = append(swiftCodes, *swift)
+	}
+	return swiftCodes, rows.Err()
+}
+
 // GetByCountryISO2 retrieves a list of SWIFT codes for a given country
 func (r *SwiftCodeRepository) GetByCountryISO2(countryISO2 string) ([]models.SwiftCode, error) {
-	query := "SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id FROM swift_codes WHERE country_iso2 = $1;"
+	query := "SELECT " + swiftCodeColumns + " FROM swift_codes WHERE country_iso2 = $1;"
 
 	rows, err := r.db.Query(query, countryISO2)
 	if err != nil {
@@ -88,14 +170,138 @@ func (r *SwiftCodeRepository) GetByCountryISO2(countryISO2 string) ([]models.Swi
 	return swiftCodes, nil
 }
 
-// DeleteSwiftCode removes a SWIFT code from the database
-func (r *SwiftCodeRepository) DeleteSwiftCode(code string) error {
-	query := "DELETE FROM swift_codes WHERE swift_code = $1;"
-	_, err := r.db.Exec(query, code)
+// ListSwiftCodes retrieves a filtered, ordered page of SWIFT codes using keyset pagination
+func (r *SwiftCodeRepository) ListSwiftCodes(filter SwiftCodeFilter) ([]models.SwiftCode, error) {
+	column, ok := SortColumns[filter.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field: %q", filter.SortBy)
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.CountryISO2 != "" {
+		addCondition("country_iso2 = $%d", filter.CountryISO2)
+	}
+	if filter.BankName != "" {
+		addCondition("bank_name ILIKE $%d", "%"+escapeLike(filter.BankName)+"%")
+	}
+	if filter.Town != "" {
+		addCondition("town_name ILIKE $%d", "%"+escapeLike(filter.Town)+"%")
+	}
+	if filter.IsHeadquarter != nil {
+		addCondition("is_headquarter = $%d", *filter.IsHeadquarter)
+	}
+	if filter.CodePrefix != "" {
+		addCondition("swift_code LIKE $%d", escapeLike(filter.CodePrefix)+"%")
+	}
+
+	comparison, direction := ">", "ASC"
+	if filter.Descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	if filter.After != nil {
+		if column == "swift_code" {
+			addCondition("swift_code "+comparison+" $%d", filter.After.SwiftCode)
+		} else {
+			args = append(args, filter.After.SortValue, filter.After.SwiftCode)
+			conditions = append(conditions, fmt.Sprintf("(%s, swift_code) %s ($%d, $%d)", column, comparison, len(args)-1, len(args)))
+		}
+	}
+
+	query := "SELECT " + swiftCodeColumns + " FROM swift_codes"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if column == "swift_code" {
+		query += fmt.Sprintf(" ORDER BY swift_code %s", direction)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, swift_code %s", column, direction, direction)
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" LIMIT $%d;", len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Println("Database query error in ListSwiftCodes:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	swiftCodes := make([]models.SwiftCode, 0, filter.Limit)
+	for rows.Next() {
+		swift, err := scanSwiftCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		swiftCodes = append(swiftCodes, *swift)
+	}
+	return swiftCodes, rows.Err()
+}
+
+// SearchSwiftCodes finds SWIFT codes whose bank name, address or town name match the query,
+// combining full-text search with trigram word similarity, best matches first
+func (r *SwiftCodeRepository) SearchSwiftCodes(query string, limit int) ([]SwiftCodeSearchResult, error) {
+	// The document expression must match idx_swift_codes_document to use the index.
+	searchQuery := `
+		WITH search AS (
+			SELECT plainto_tsquery('simple', $1) AS ts_query
+		)
+		SELECT ` + swiftCodeColumns + `,
+			ts_rank(to_tsvector('simple', bank_name || ' ' || COALESCE(address, '') || ' ' || COALESCE(town_name, '')), search.ts_query)
+				+ GREATEST(
+					word_similarity($1, bank_name),
+					word_similarity($1, COALESCE(address, '')),
+					word_similarity($1, COALESCE(town_name, ''))
+				) AS score
+		FROM swift_codes, search
+		WHERE to_tsvector('simple', bank_name || ' ' || COALESCE(address, '') || ' ' || COALESCE(town_name, '')) @@ search.ts_query
+		OR $1 <% bank_name
+		OR $1 <% address
+		OR $1 <% town_name
+		ORDER BY score DESC, swift_code
+		LIMIT $2;
+	`
+
+	rows, err := r.db.Query(searchQuery, query, limit)
+	if err != nil {
+		log.Println("Database query error in SearchSwiftCodes:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]SwiftCodeSearchResult, 0)
+	for rows.Next() {
+		var score float64
+		swift, err := scanSwiftCode(rows, &score)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SwiftCodeSearchResult{Record: *swift, Score: score})
+	}
+	return results, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in user input
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// DeleteSwiftCode removes a SWIFT code from the database. A record that no longer
+// has the given version is kept and reported as ErrVersionConflict.
+func (r *SwiftCodeRepository) DeleteSwiftCode(code string, version int64) error {
+	query := "DELETE FROM swift_codes WHERE swift_code = $1 AND version = $2;"
+	result, err := r.db.Exec(query, code, version)
 	if err != nil {
 		log.Println("Error deleting SWIFT code:", err)
+		return err
 	}
-	return err
+	return checkVersionMatched(result, code)
 }
 
 // DetachBranchesFromHeadquarter detaches all branches from a given headquarter
@@ -108,18 +314,53 @@ func (r *SwiftCodeRepository) DetachBranchesFromHeadquarter(headquarterID int64)
 	return err
 }
 
-// InsertSwiftCode inserts a new SWIFT code record into the database
+// InsertSwiftCode inserts a new SWIFT code record into the database.
+// An existing SWIFT code is reported as ErrDuplicateSwiftCode. The conflict is skipped
+// rather than raised, so that it does not abort an enclosing transaction.
 func (r *SwiftCodeRepository) InsertSwiftCode(swift *models.SwiftCode) error {
-	query := "INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;"
+	query := "INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (swift_code) DO NOTHING RETURNING id;"
 
 	err := r.db.QueryRow(query, swift.SwiftCode, swift.BankName, swift.Address,
-		swift.CountryISO2, swift.CountryName, swift.IsHeadquarter, swift.HeadquarterID).Scan(&swift.ID)
+		swift.CountryISO2, swift.CountryName, swift.IsHeadquarter, swift.HeadquarterID,
+		swift.CodeType, swift.TownName, swift.TimeZone).Scan(&swift.ID)
+	if err == sql.ErrNoRows || isUniqueViolation(err) {
+		return fmt.Errorf("%w: %s", ErrDuplicateSwiftCode, swift.SwiftCode)
+	}
 	if err != nil {
 		log.Println("Error inserting new SWIFT code in InsertSwiftCode:", err)
 	}
 	return err
 }
 
+// UpdateSwiftCode overwrites the mutable fields of an existing SWIFT code record.
+// swift.Version is the version the record was read with; a record changed since is
+// left untouched and reported as ErrVersionConflict.
+func (r *SwiftCodeRepository) UpdateSwiftCode(swift *models.SwiftCode) error {
+	query := "UPDATE swift_codes SET bank_name = $2, address = $3, country_iso2 = $4, country_name = $5, is_headquarter = $6, headquarter_id = $7, code_type = $8, town_name = $9, time_zone = $10 WHERE swift_code = $1 AND version = $11;"
+
+	result, err := r.db.Exec(query, swift.SwiftCode, swift.BankName, swift.Address,
+		swift.CountryISO2, swift.CountryName, swift.IsHeadquarter, swift.HeadquarterID,
+		swift.CodeType, swift.TownName, swift.TimeZone, swift.Version)
+	if err != nil {
+		log.Println("Error updating SWIFT code in UpdateSwiftCode:", err)
+		return err
+	}
+	return checkVersionMatched(result, swift.SwiftCode)
+}
+
+// checkVersionMatched reports ErrVersionConflict when a statement guarded by a version
+// affected no row.
+func checkVersionMatched(result sql.Result, code string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", ErrVersionConflict, code)
+	}
+	return nil
+}
+
 // GetBranchesByHeadquarter retrieves branches associated with a given headquarter
 func (r *SwiftCodeRepository) GetBranchesByHeadquarter(headquarterCode string) ([]models.SwiftCode, error) {
 	var headquarterID int
@@ -135,7 +376,7 @@ func (r *SwiftCodeRepository) GetBranchesByHeadquarter(headquarterCode string) (
 	}
 
 	// Retrieve branches associated with the headquarter
-	query := "SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id FROM swift_codes WHERE headquarter_id = $1;"
+	query := "SELECT " + swiftCodeColumns + " FROM swift_codes WHERE headquarter_id = $1;"
 	rows, err := r.db.Query(query, headquarterID)
 	if err != nil {
 		log.Println("Error fetching branches in GetBranchesByHeadquarter:", err)
@@ -184,3 +425,46 @@ func (r *SwiftCodeRepository) AssignBranchesToHeadquarter(headquarterCode string
 
 	return nil
 }
+
+// FindHeadquarterLinkIssues lists branches whose headquarter_id does not point at the
+// headquarter sharing their 8-character SWIFT code prefix
+func (r *SwiftCodeRepository) FindHeadquarterLinkIssues() ([]models.HeadquarterLinkIssue, error) {
+	query := `
+		SELECT branch.swift_code, branch.headquarter_id, linked.swift_code, linked.is_headquarter, expected.swift_code
+		FROM swift_codes AS branch
+		LEFT JOIN swift_codes AS linked ON linked.id = branch.headquarter_id
+		LEFT JOIN swift_codes AS expected
+			ON expected.swift_code = LEFT(branch.swift_code, 8) || 'XXX' AND expected.is_headquarter = true
+		WHERE branch.is_headquarter = false
+		AND branch.headquarter_id IS DISTINCT FROM expected.id
+		ORDER BY branch.swift_code;
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Println("Error checking headquarter links in FindHeadquarterLinkIssues:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	issues := make([]models.HeadquarterLinkIssue, 0)
+	for rows.Next() {
+		var issue models.HeadquarterLinkIssue
+		var linkedIsHeadquarter sql.NullBool
+		if err := rows.Scan(&issue.SwiftCode, &issue.HeadquarterID, &issue.LinkedSwiftCode, &linkedIsHeadquarter, &issue.ExpectedHeadquarter); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case issue.HeadquarterID == nil:
+			issue.Problem = models.LinkIssueMissing
+		case issue.LinkedSwiftCode == nil:
+			issue.Problem = models.LinkIssueDangling
+		case (*issue.LinkedSwiftCode)[:8] != issue.SwiftCode[:8]:
+			issue.Problem = models.LinkIssueWrongInstitution
+		default:
+			issue.Problem = models.LinkIssueNotHeadquarter
+		}
+		issues = append(issues, issue)
+	}
+	return issues, rows.Err()
+}
@@ -2,23 +2,40 @@ package routes
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/middleware"
 	"github.com/mroczekDNF/swift-api/internal/repositories"
 )
 
-// SetupRouter defines API endpoints
-func SetupRouter(db *sql.DB) *gin.Engine {
-	router := gin.Default()
+// SetupRouter defines API endpoints. Responses to mutating requests with an
+// Idempotency-Key header are replayed to retries for idempotencyWindow; reads and
+// the lookup, which changes nothing, ignore the header.
+func SetupRouter(db *sql.DB, idempotencyWindow time.Duration) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Logger(), middleware.RequestID(), gin.CustomRecovery(handlers.Recovery))
+	router.NoRoute(handlers.NoRoute)
 
 	repo := repositories.NewSwiftCodeRepository(db)
 	handler := handlers.NewSwiftCodeHandler(repo)
+	importHandler := handlers.NewImportHandler(db)
+	idempotent := handlers.Idempotency(repositories.NewIdempotencyKeyRepository(db), idempotencyWindow)
 
+	router.GET("/v1/swift-codes", handler.ListSwiftCodes)
+	router.GET("/v1/swift-codes/search", handler.SearchSwiftCodes)
+	router.GET("/v1/swift-codes/export", handler.ExportSwiftCodes)
 	router.GET("/v1/swift-codes/:swiftCode", handler.GetSwiftCodeDetails)
 	router.GET("/v1/swift-codes/country/:countryISO2", handler.GetSwiftCodesByCountry)
-	router.POST("/v1/swift-codes", handler.AddSwiftCode)
-	router.DELETE("/v1/swift-codes/:swift-code", handler.DeleteSwiftCode)
+	router.POST("/v1/swift-codes", idempotent, handler.AddSwiftCode)
+	router.POST("/v1/swift-codes/batch", idempotent, handler.AddSwiftCodesBatch)
+	router.POST("/v1/swift-codes/lookup", handler.LookupSwiftCodes)
+	router.PUT("/v1/swift-codes/:swiftCode", idempotent, handler.UpdateSwiftCode)
+	router.PATCH("/v1/swift-codes/:swiftCode", idempotent, handler.PatchSwiftCode)
+	router.DELETE("/v1/swift-codes/:swift-code", idempotent, handler.DeleteSwiftCode)
+	router.GET("/v1/integrity/headquarter-links", handler.GetHeadquarterLinkIssues)
+	router.POST("/v1/imports", idempotent, importHandler.ImportSwiftCodes)
 
 	return router
 }
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Field identifies a column of a SWIFT directory file by its meaning rather than its position.
+type Field string
+
+// Fields of the SWIFT directory file.
+const (
+	FieldCountryISO2 Field = "countryISO2"
+	FieldSwiftCode   Field = "swiftCode"
+	FieldCodeType    Field = "codeType"
+	FieldBankName    Field = "bankName"
+	FieldAddress     Field = "address"
+	FieldTownName    Field = "townName"
+	FieldCountryName Field = "countryName"
+	FieldTimeZone    Field = "timeZone"
+)
+
+// fieldColumns gives the position of each field in the canonical record layout used by the parser.
+var fieldColumns = map[Field]int{
+	FieldCountryISO2: ColumnCountryISO2,
+	FieldSwiftCode:   ColumnSwiftCode,
+	FieldCodeType:    CodeType,
+	FieldBankName:    ColumnBankName,
+	FieldAddress:     ColumnAddress,
+	FieldTownName:    ColumnTownName,
+	FieldCountryName: ColumnCountryName,
+	FieldTimeZone:    ColumnTimeZone,
+}
+
+// fieldAt returns the field at a position of the canonical record layout.
+func fieldAt(position int) Field {
+	for field, column := range fieldColumns {
+		if column == position {
+			return field
+		}
+	}
+	return ""
+}
+
+// optionalFields may be missing from a file; their values are then empty.
+var optionalFields = map[Field]bool{
+	FieldAddress:  true,
+	FieldTownName: true,
+	FieldTimeZone: true,
+}
+
+// ColumnMapping lists, for each field, the header names it may appear under.
+// Header names are matched ignoring case and repeated whitespace.
+type ColumnMapping map[Field][]string
+
+// DefaultColumnMapping recognises the SWIFT directory layout and common vendor variants.
+var DefaultColumnMapping = ColumnMapping{
+	FieldCountryISO2: {"COUNTRY ISO2 CODE", "COUNTRY ISO2", "ISO2", "COUNTRY CODE"},
+	FieldSwiftCode:   {"SWIFT CODE", "SWIFT", "BIC", "BIC CODE", "SWIFT/BIC"},
+	FieldCodeType:    {"CODE TYPE", "BIC TYPE", "TYPE"},
+	FieldBankName:    {"NAME", "BANK NAME", "INSTITUTION NAME", "INSTITUTION"},
+	FieldAddress:     {"ADDRESS", "STREET ADDRESS"},
+	FieldTownName:    {"TOWN NAME", "TOWN", "CITY", "CITY NAME"},
+	FieldCountryName: {"COUNTRY NAME", "COUNTRY"},
+	FieldTimeZone:    {"TIME ZONE", "TIMEZONE", "TZ"},
+}
+
+// With returns a mapping accepting the aliases of both m and extra.
+func (m ColumnMapping) With(extra ColumnMapping) ColumnMapping {
+	merged := make(ColumnMapping, len(m))
+	for field, aliases := range m {
+		merged[field] = append([]string(nil), aliases...)
+	}
+	for field, aliases := range extra {
+		merged[field] = append(merged[field], aliases...)
+	}
+	return merged
+}
+
+// LoadColumnMapping reads a JSON object mapping field names to lists of header aliases,
+// e.g. {"swiftCode": ["BIC11"], "bankName": ["INSTITUTION LEGAL NAME"]}.
+// The aliases extend DefaultColumnMapping.
+func LoadColumnMapping(path string) (ColumnMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseColumnMapping(data)
+}
+
+// ParseColumnMapping decodes a JSON column mapping as read by LoadColumnMapping.
+func ParseColumnMapping(data []byte) (ColumnMapping, error) {
+	var extra ColumnMapping
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return nil, fmt.Errorf("invalid column mapping: %w", err)
+	}
+	for field := range extra {
+		if _, ok := fieldColumns[field]; !ok {
+			return nil, fmt.Errorf("invalid column mapping: unknown field %q", field)
+		}
+	}
+	mapping := DefaultColumnMapping.With(extra)
+	if _, err := mapping.aliasFields(); err != nil {
+		return nil, fmt.Errorf("invalid column mapping: %w", err)
+	}
+	return mapping, nil
+}
+
+// aliasFields indexes the fields of m by normalised header name. A header name assigned
+// to more than one field is rejected, since it would make the column's meaning depend
+// on map iteration order.
+func (m ColumnMapping) aliasFields() (map[string]Field, error) {
+	fields := make([]Field, 0, len(m))
+	for field := range m {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fieldColumns[fields[i]] < fieldColumns[fields[j]] })
+
+	aliases := make(map[string]Field)
+	for _, field := range fields {
+		for _, name := range m[field] {
+			alias := normalizeHeader(name)
+			if previous, ok := aliases[alias]; ok && previous != field {
+				return nil, fmt.Errorf("header %q is assigned to both %s and %s", alias, previous, field)
+			}
+			aliases[alias] = field
+		}
+	}
+	return aliases, nil
+}
+
+// MissingColumnsError reports required fields not found in a file header.
+type MissingColumnsError struct {
+	Missing []Field
+	Mapping ColumnMapping
+}
+
+func (e *MissingColumnsError) Error() string {
+	descriptions := make([]string, 0, len(e.Missing))
+	for _, field := range e.Missing {
+		descriptions = append(descriptions, fmt.Sprintf("%s (expected one of: %s)", field, strings.Join(e.Mapping[field], ", ")))
+	}
+	return "missing required columns: " + strings.Join(descriptions, "; ")
+}
+
+// columnLayout maps the columns of a file to the canonical record layout.
+type columnLayout struct {
+	positions map[int]int // canonical position -> column in the file
+	width     int         // number of columns in the header
+}
+
+// resolveColumns finds the column of every field in header. Unknown columns are ignored.
+func resolveColumns(header []string, mapping ColumnMapping) (columnLayout, error) {
+	aliases, err := mapping.aliasFields()
+	if err != nil {
+		return columnLayout{}, err
+	}
+
+	layout := columnLayout{positions: make(map[int]int), width: len(header)}
+	found := make(map[Field]int)
+	for column, name := range header {
+		field, ok := aliases[normalizeHeader(name)]
+		if !ok {
+			continue
+		}
+		if previous, duplicate := found[field]; duplicate {
+			return columnLayout{}, fmt.Errorf("columns %d and %d both map to %s", previous+1, column+1, field)
+		}
+		found[field] = column
+		layout.positions[fieldColumns[field]] = column
+	}
+
+	var missing []Field
+	for field := range fieldColumns {
+		if _, ok := found[field]; !ok && !optionalFields[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Slice(missing, func(i, j int) bool { return fieldColumns[missing[i]] < fieldColumns[missing[j]] })
+		return columnLayout{}, &MissingColumnsError{Missing: missing, Mapping: mapping}
+	}
+	return layout, nil
+}
+
+// checkWidth checks the number of fields of a file record against the header. A record with
+// fewer fields is rejected, since its values may have shifted columns; fields beyond the
+// header are ignored like unknown columns.
+func (l columnLayout) checkWidth(fields int) (string, string) {
+	if fields < l.width {
+		return ReasonInsufficientData, fmt.Sprintf("insufficient data: %d of %d fields", fields, l.width)
+	}
+	return "", ""
+}
+
+// canonical reorders a file record into the canonical layout; absent columns are empty.
+func (l columnLayout) canonical(record []string) []string {
+	result := make([]string, len(fieldColumns))
+	for position, column := range l.positions {
+		if column < len(record) {
+			result[position] = record[column]
+		}
+	}
+	return result
+}
+
+func normalizeHeader(name string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+}
@@ -1,26 +1,359 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/mroczekDNF/swift-api/internal/models"
 )
 
-// SaveSwiftCodesToDatabase zapisuje dane SWIFT do bazy danych
+const (
+	// insertBatchSize is the number of rows per multi-row INSERT when COPY is unavailable.
+	insertBatchSize = 1000
+	// progressInterval is the number of rows between progress log lines.
+	progressInterval = 10000
+)
+
+// swiftCodeColumns lists the columns written by the bulk loader, in value order.
+// headquarter_id is resolved after the load from the real ids, see headquarterLinkQueries.
+var swiftCodeColumns = []string{"swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "code_type", "town_name", "time_zone"}
+
+// headquarterLinkQueries point every branch at the headquarter sharing its 8-character
+// prefix and clear links that no longer match an existing headquarter.
+var headquarterLinkQueries = []string{
+	`UPDATE swift_codes AS branch
+	SET headquarter_id = hq.id
+	FROM swift_codes AS hq
+	WHERE branch.is_headquarter = false
+		AND hq.is_headquarter = true
+		AND hq.swift_code = LEFT(branch.swift_code, 8) || 'XXX'
+		AND branch.headquarter_id IS DISTINCT FROM hq.id;`,
+	`UPDATE swift_codes AS branch
+	SET headquarter_id = NULL
+	WHERE branch.headquarter_id IS NOT NULL
+		AND (branch.is_headquarter = true OR NOT EXISTS (
+			SELECT 1 FROM swift_codes AS hq
+			WHERE hq.id = branch.headquarter_id
+				AND hq.is_headquarter = true
+				AND hq.swift_code = LEFT(branch.swift_code, 8) || 'XXX'
+		));`,
+}
+
+// errCopyUnsupported is returned when the connection is not backed by pgx.
+var errCopyUnsupported = errors.New("connection does not support COPY")
+
+// SaveSwiftCodesToDatabase zapisuje dane SWIFT do bazy danych.
+// Rows are streamed with PostgreSQL COPY inside a single transaction, so a failure leaves the table untouched.
+// Drivers other than pgx fall back to batched multi-row INSERTs in one transaction.
+// Branches are linked to their headquarters by SWIFT code prefix in the same transaction.
 func SaveSwiftCodesToDatabase(db *sql.DB, swiftCodes []models.SwiftCode) error {
-	for _, code := range swiftCodes {
-		query := `INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`
+	ctx := context.Background()
+
+	err := copySwiftCodes(ctx, db, swiftCodes)
+	if errors.Is(err, errCopyUnsupported) {
+		err = insertSwiftCodesInBatches(ctx, db, swiftCodes)
+	}
+	if err != nil {
+		log.Printf("Błąd zapisu SWIFT codes: %v", err)
+		return err
+	}
+
+	log.Printf("Wszystkie SWIFT codes zapisane w bazie (%d).", len(swiftCodes))
+	return nil
+}
+
+// copySwiftCodes loads the records with pgx CopyFrom in a single transaction.
+func copySwiftCodes(ctx context.Context, db *sql.DB, swiftCodes []models.SwiftCode) error {
+	return inCopyTx(ctx, db, func(tx pgx.Tx) error {
+		copied, err := tx.CopyFrom(ctx, pgx.Identifier{"swift_codes"}, swiftCodeColumns, &swiftCodeCopySource{rows: swiftCodes})
+		if err != nil {
+			return err
+		}
+		log.Printf("Copied %d SWIFT codes, linking branches to headquarters...", copied)
+		return nil
+	})
+}
 
-		var id int64
-		err := db.QueryRow(query, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter, code.HeadquarterID).Scan(&id)
+// inCopyTx runs load in a pgx transaction, then links branches to their headquarters and commits.
+// It returns errCopyUnsupported when the connection is not backed by pgx.
+func inCopyTx(ctx context.Context, db *sql.DB, load func(tx pgx.Tx) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errCopyUnsupported
+		}
+
+		tx, err := stdlibConn.Conn().Begin(ctx)
 		if err != nil {
-			log.Printf("Błąd zapisu SWIFT code %s: %v", code.SwiftCode, err)
 			return err
 		}
+		defer tx.Rollback(ctx)
+
+		if err := load(tx); err != nil {
+			return err
+		}
+
+		for _, query := range headquarterLinkQueries {
+			if _, err := tx.Exec(ctx, query); err != nil {
+				return err
+			}
+		}
+
+		return tx.Commit(ctx)
+	})
+}
+
+// swiftCodeCopySource feeds records to CopyFrom and logs progress.
+type swiftCodeCopySource struct {
+	rows []models.SwiftCode
+	idx  int
+}
+
+func (s *swiftCodeCopySource) Next() bool {
+	if s.idx > 0 && s.idx%progressInterval == 0 {
+		log.Printf("Loading SWIFT codes: %d/%d", s.idx, len(s.rows))
 	}
+	s.idx++
+	return s.idx <= len(s.rows)
+}
 
-	log.Println("Wszystkie SWIFT codes zapisane w bazie.")
+func (s *swiftCodeCopySource) Values() ([]any, error) {
+	return swiftCodeValues(s.rows[s.idx-1]), nil
+}
+
+// swiftCodeValues returns the values of a record in swiftCodeColumns order.
+func swiftCodeValues(code models.SwiftCode) []any {
+	return []any{code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter,
+		code.CodeType, code.TownName, code.TimeZone}
+}
+
+func (s *swiftCodeCopySource) Err() error {
 	return nil
 }
+
+// insertSwiftCodesInBatches loads the records with multi-row INSERTs in a single transaction.
+func insertSwiftCodesInBatches(ctx context.Context, db *sql.DB, swiftCodes []models.SwiftCode) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(swiftCodes); start += insertBatchSize {
+		end := min(start+insertBatchSize, len(swiftCodes))
+		if err := insertSwiftCodeBatch(ctx, tx, swiftCodes[start:end]); err != nil {
+			return err
+		}
+
+		if end%progressInterval < insertBatchSize || end == len(swiftCodes) {
+			log.Printf("Loading SWIFT codes: %d/%d", end, len(swiftCodes))
+		}
+	}
+
+	for _, query := range headquarterLinkQueries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertSwiftCodeBatch inserts up to insertBatchSize records with one multi-row INSERT.
+func insertSwiftCodeBatch(ctx context.Context, tx *sql.Tx, batch []models.SwiftCode) error {
+	placeholders := make([]string, 0, len(batch))
+	args := make([]any, 0, len(batch)*len(swiftCodeColumns))
+	for i, code := range batch {
+		base := i * len(swiftCodeColumns)
+		params := make([]string, len(swiftCodeColumns))
+		for j := range params {
+			params[j] = fmt.Sprintf("$%d", base+j+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(params, ", ")+")")
+		args = append(args, swiftCodeValues(code)...)
+	}
+
+	query := fmt.Sprintf("INSERT INTO swift_codes (%s) VALUES %s;", strings.Join(swiftCodeColumns, ", "), strings.Join(placeholders, ", "))
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// LoadSwiftCodesFromReader streams SWIFT data from r into the database without holding the file
+// in memory, see StreamSwiftCodes. Like SaveSwiftCodesToDatabase it loads the rows with COPY, or
+// batched INSERTs for drivers other than pgx, in a single transaction. Repeated SWIFT codes are
+// resolved with policy and branches are linked to their headquarters before committing.
+// The parse report is returned even when the load fails.
+func LoadSwiftCodesFromReader(db *sql.DB, r io.Reader, mapping ColumnMapping, policy DuplicatePolicy, opts StreamOptions) (ParseReport, error) {
+	ctx := context.Background()
+
+	report, err := streamCopySwiftCodes(ctx, db, r, mapping, policy, opts)
+	if errors.Is(err, errCopyUnsupported) {
+		report, err = streamInsertSwiftCodes(ctx, db, r, mapping, policy, opts)
+	}
+	if err != nil {
+		log.Printf("Błąd zapisu SWIFT codes: %v", err)
+		return report, err
+	}
+
+	log.Printf("Wszystkie SWIFT codes zapisane w bazie (%d).", report.Accepted-len(report.Duplicates)-len(report.Discarded))
+	return report, nil
+}
+
+// streamCopySwiftCodes pipes the parsed batches into pgx CopyFrom.
+func streamCopySwiftCodes(ctx context.Context, db *sql.DB, r io.Reader, mapping ColumnMapping, policy DuplicatePolicy, opts StreamOptions) (ParseReport, error) {
+	var report ParseReport
+	err := inCopyTx(ctx, db, func(tx pgx.Tx) error {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		source := &streamCopySource{batches: make(chan []models.SwiftCode, 1)}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer close(source.batches)
+			report, source.err = StreamSwiftCodes(streamCtx, r, mapping, policy, opts, func(batch []models.SwiftCode) error {
+				select {
+				case source.batches <- batch:
+					return nil
+				case <-streamCtx.Done():
+					return streamCtx.Err()
+				}
+			})
+		}()
+
+		copied, err := tx.CopyFrom(ctx, pgx.Identifier{"swift_codes"}, swiftCodeColumns, source)
+		// Stop the parser if COPY failed before consuming everything. The parser then reports
+		// the cancellation, so the COPY error comes first; it also carries parser errors, which
+		// CopyFrom takes from source.Err.
+		cancel()
+		<-done
+		if err != nil {
+			return err
+		}
+		if source.err != nil {
+			return source.err
+		}
+		log.Printf("Copied %d SWIFT codes, linking branches to headquarters...", copied)
+
+		for _, statement := range duplicateResolutionStatements(report) {
+			if _, err := tx.Exec(ctx, statement.query, statement.args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return report, err
+}
+
+// streamCopySource feeds CopyFrom from the batches of StreamSwiftCodes and logs progress.
+// err is set before batches is closed.
+type streamCopySource struct {
+	batches chan []models.SwiftCode
+	batch   []models.SwiftCode
+	idx     int
+	copied  int
+	err     error
+}
+
+func (s *streamCopySource) Next() bool {
+	s.idx++
+	for s.idx > len(s.batch) {
+		batch, ok := <-s.batches
+		if !ok {
+			return false
+		}
+		s.batch, s.idx = batch, 1
+	}
+	if s.copied++; s.copied%progressInterval == 0 {
+		log.Printf("Loading SWIFT codes: %d", s.copied)
+	}
+	return true
+}
+
+func (s *streamCopySource) Values() ([]any, error) {
+	return swiftCodeValues(s.batch[s.idx-1]), nil
+}
+
+func (s *streamCopySource) Err() error {
+	return s.err
+}
+
+// streamInsertSwiftCodes inserts the parsed batches with multi-row INSERTs in a single transaction.
+func streamInsertSwiftCodes(ctx context.Context, db *sql.DB, r io.Reader, mapping ColumnMapping, policy DuplicatePolicy, opts StreamOptions) (ParseReport, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return ParseReport{}, err
+	}
+	defer tx.Rollback()
+
+	loaded := 0
+	report, err := StreamSwiftCodes(ctx, r, mapping, policy, opts, func(batch []models.SwiftCode) error {
+		for start := 0; start < len(batch); start += insertBatchSize {
+			end := min(start+insertBatchSize, len(batch))
+			if err := insertSwiftCodeBatch(ctx, tx, batch[start:end]); err != nil {
+				return err
+			}
+		}
+		if loaded/progressInterval != (loaded+len(batch))/progressInterval {
+			log.Printf("Loading SWIFT codes: %d", loaded+len(batch))
+		}
+		loaded += len(batch)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for _, statement := range duplicateResolutionStatements(report) {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return report, err
+		}
+	}
+
+	for _, query := range headquarterLinkQueries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return report, err
+		}
+	}
+
+	return report, tx.Commit()
+}
+
+// statement is a query with its arguments.
+type statement struct {
+	query string
+	args  []any
+}
+
+// duplicateResolutionStatements apply the duplicate resolution of a streamed report to the
+// rows loaded from the first occurrences: replaced rows are overwritten and discarded ones deleted.
+func duplicateResolutionStatements(report ParseReport) []statement {
+	var statements []statement
+	if len(report.Replacements) > 0 {
+		assignments := make([]string, 0, len(swiftCodeColumns)-1)
+		for i, column := range swiftCodeColumns[1:] {
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", column, i+2))
+		}
+		query := fmt.Sprintf("UPDATE swift_codes SET %s WHERE swift_code = $1;", strings.Join(assignments, ", "))
+		for _, code := range report.Replacements {
+			statements = append(statements, statement{query, swiftCodeValues(code)})
+		}
+	}
+	if len(report.Discarded) > 0 {
+		statements = append(statements, statement{"DELETE FROM swift_codes WHERE swift_code = ANY($1);", []any{report.Discarded}})
+	}
+	return statements
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+)
+
+// DuplicatePolicy decides which record is kept when a SWIFT code appears more than once in a file.
+type DuplicatePolicy string
+
+// Duplicate resolution policies. Identical duplicates are always collapsed into one record;
+// the policy only matters for conflicting ones.
+const (
+	DuplicateFirstWins DuplicatePolicy = "first"  // Keep the first occurrence
+	DuplicateLastWins  DuplicatePolicy = "last"   // Keep the last occurrence
+	DuplicateReject    DuplicatePolicy = "reject" // Load none of the records of a conflicting code
+)
+
+// Kinds of duplicate records.
+const (
+	DuplicateIdentical   = "IDENTICAL"   // Same values as the first occurrence
+	DuplicateConflicting = "CONFLICTING" // Values differ from the first occurrence, e.g. another bank name or address
+)
+
+// ParseDuplicatePolicy returns the policy with the given name. An empty name selects DuplicateFirstWins.
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return DuplicateFirstWins, nil
+	case DuplicateFirstWins, DuplicateLastWins, DuplicateReject:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unsupported duplicate policy %q (expected one of: first, last, reject)", name)
+}
+
+// seenSwiftCode is the first occurrence of a SWIFT code in a file. Only a hash of the
+// record is kept, so that tracking a large file stays cheap.
+type seenSwiftCode struct {
+	line int
+	hash uint64
+}
+
+// duplicateTracker detects repeated SWIFT codes while a file is read in order.
+type duplicateTracker struct {
+	seen        map[uint64]seenSwiftCode    // By swiftCodeKey
+	latest      map[string]models.SwiftCode // Last occurrence of each repeated code
+	conflicting map[string]bool
+	duplicates  []DuplicateRecord
+}
+
+func newDuplicateTracker() *duplicateTracker {
+	return &duplicateTracker{
+		seen:        make(map[uint64]seenSwiftCode),
+		latest:      make(map[string]models.SwiftCode),
+		conflicting: make(map[string]bool),
+		duplicates:  []DuplicateRecord{},
+	}
+}
+
+// track records a valid record read from line and reports whether it is the first occurrence of its code.
+func (t *duplicateTracker) track(code models.SwiftCode, line int) bool {
+	key := swiftCodeKey(code.SwiftCode)
+	hash := swiftCodeHash(code)
+	first, seen := t.seen[key]
+	if !seen {
+		t.seen[key] = seenSwiftCode{line: line, hash: hash}
+		return true
+	}
+
+	kind := DuplicateIdentical
+	if hash != first.hash {
+		kind = DuplicateConflicting
+		t.conflicting[code.SwiftCode] = true
+	}
+	t.duplicates = append(t.duplicates, DuplicateRecord{Line: line, FirstLine: first.line, SwiftCode: code.SwiftCode, Kind: kind})
+	t.latest[code.SwiftCode] = code
+	return false
+}
+
+// resolve applies policy to the repeated codes and records the outcome in report: the records
+// replacing a first occurrence under DuplicateLastWins, and the codes left out under DuplicateReject.
+func (t *duplicateTracker) resolve(policy DuplicatePolicy, report *ParseReport) {
+	report.Duplicates = t.duplicates
+	report.Replacements = []models.SwiftCode{}
+	report.Discarded = []string{}
+
+	switch policy {
+	case DuplicateLastWins:
+		for _, duplicate := range t.duplicates {
+			code, ok := t.latest[duplicate.SwiftCode]
+			if !ok {
+				continue // already replaced
+			}
+			delete(t.latest, duplicate.SwiftCode)
+			if swiftCodeHash(code) != t.seen[swiftCodeKey(code.SwiftCode)].hash {
+				report.Replacements = append(report.Replacements, code)
+			}
+		}
+	case DuplicateReject:
+		for code := range t.conflicting {
+			report.Discarded = append(report.Discarded, code)
+		}
+		sort.Strings(report.Discarded)
+	}
+}
+
+// applyResolution returns the first occurrences in swiftCodes with the outcome of resolve applied.
+func applyResolution(swiftCodes []models.SwiftCode, report ParseReport) []models.SwiftCode {
+	replacements := make(map[string]models.SwiftCode, len(report.Replacements))
+	for _, code := range report.Replacements {
+		replacements[code.SwiftCode] = code
+	}
+	discarded := make(map[string]bool, len(report.Discarded))
+	for _, code := range report.Discarded {
+		discarded[code] = true
+	}
+
+	resolved := swiftCodes[:0]
+	for _, code := range swiftCodes {
+		if discarded[code.SwiftCode] {
+			continue
+		}
+		if replacement, ok := replacements[code.SwiftCode]; ok {
+			code = replacement
+		}
+		resolved = append(resolved, code)
+	}
+	return resolved
+}
+
+// swiftCodeHash hashes the values of a record written to the database.
+func swiftCodeHash(code models.SwiftCode) uint64 {
+	hash := fnv.New64a()
+	for _, value := range []string{code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName,
+		strconv.FormatBool(code.IsHeadquarter), code.CodeType, code.TownName, code.TimeZone} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Encoding is the character encoding of an input file.
+type Encoding string
+
+// Supported input encodings.
+const (
+	EncodingAuto        Encoding = "auto"
+	EncodingUTF8        Encoding = "utf-8"
+	EncodingWindows1250 Encoding = "windows-1250"
+	EncodingISO88591    Encoding = "iso-8859-1"
+)
+
+// encodingSampleSize is the number of bytes inspected to detect the encoding.
+const encodingSampleSize = 64 << 10
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// windows1250Evidence weighs the bytes that Windows-1250 and ISO-8859-1 decode differently;
+// positive weights favour Windows-1250. In 0x80-0xBF Windows-1250 has Central European
+// letters where ISO-8859-1 has control characters or symbols (e.g. 0xB9 is "ą" or "¹"), which
+// is strong evidence. In 0xC0-0xFF both have letters, and a byte leans towards the encoding
+// in which its letter is the more common one in names: 0xEA is the frequent Polish "ę" but
+// the rarer "ê", while 0xE0 is the rare Slovak "ŕ" but the frequent "à".
+var windows1250Evidence = [256]int{
+	0x8A: 4, 0x8C: 4, 0x8D: 4, 0x8E: 4, 0x8F: 4, // Š Ś Ť Ž Ź
+	0x9A: 4, 0x9C: 4, 0x9D: 4, 0x9E: 4, 0x9F: 4, // š ś ť ž ź
+	0xA3: 4, 0xA5: 4, 0xAA: 4, 0xAF: 4, // Ł Ą Ş Ż
+	0xB3: 4, 0xB9: 4, 0xBA: 4, 0xBF: 4, // ł ą ş ż
+
+	0xC0: -2, 0xE0: -2, // Ŕ ŕ or À à
+	0xC5: -2, 0xE5: -2, // Ĺ ĺ or Å å
+	0xC6: 1, 0xE6: 1, // Ć ć or Æ æ
+	0xCA: 2, 0xEA: 2, // Ę ę or Ê ê
+	0xCC: 3, 0xEC: 3, // Ě ě or Ì ì
+	0xCF: 1, 0xEF: 1, // Ď ď or Ï ï
+	0xD1: 1, 0xF1: 1, // Ń ń or Ñ ñ
+	0xD2: 1, 0xF2: 1, // Ň ň or Ò ò
+	0xD9: 1, 0xF9: 1, // Ů ů or Ù ù
+}
+
+// ParseEncoding returns the encoding with the given name. An empty name selects EncodingAuto.
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "windows-1250", "cp1250":
+		return EncodingWindows1250, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		return EncodingISO88591, nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q (expected one of: auto, utf-8, windows-1250, iso-8859-1)", name)
+}
+
+// DecodeInput returns a reader of r transcoded to UTF-8, together with the source encoding.
+// A UTF-8 byte order mark is stripped. With EncodingAuto the encoding is detected from the
+// start of the input: UTF-8 when it has a byte order mark or holds valid multi-byte sequences,
+// otherwise Windows-1250 or ISO-8859-1, whichever the letters found suit better. When the
+// start is plain ASCII, the sample is taken from the first non-ASCII byte instead, which
+// requires r to be an io.Seeker, as files are; detection fails for other readers then.
+// Invalid UTF-8 sequences are passed through and rejected per record by the parser.
+func DecodeInput(r io.Reader, enc Encoding) (io.Reader, Encoding, error) {
+	var start int64
+	seeker, seekable := r.(io.Seeker)
+	if seekable && enc == EncodingAuto {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return nil, "", err
+		}
+	}
+
+	buffered := bufio.NewReaderSize(r, encodingSampleSize)
+	sample, err := buffered.Peek(encodingSampleSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", err
+	}
+
+	if bytes.HasPrefix(sample, utf8BOM) {
+		if enc != EncodingAuto && enc != EncodingUTF8 {
+			return nil, "", fmt.Errorf("input starts with a UTF-8 byte order mark but was declared as %s", enc)
+		}
+		enc = EncodingUTF8
+		buffered.Discard(len(utf8BOM))
+	}
+	if enc == EncodingAuto && len(sample) == encodingSampleSize && isASCII(sample) {
+		// The rest of the input may still be in an 8-bit encoding.
+		if !seekable {
+			return nil, "", fmt.Errorf("the first %d KB of the input are ASCII; declare its encoding", encodingSampleSize>>10)
+		}
+		if sample, err = sampleNonASCII(buffered); err != nil {
+			return nil, "", err
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, "", err
+		}
+		buffered.Reset(r)
+	}
+	if enc == EncodingAuto {
+		enc = detectEncoding(sample)
+	}
+
+	switch enc {
+	case EncodingUTF8:
+		return buffered, enc, nil
+	case EncodingWindows1250:
+		return transform.NewReader(buffered, charmap.Windows1250.NewDecoder()), enc, nil
+	case EncodingISO88591:
+		return transform.NewReader(buffered, charmap.ISO8859_1.NewDecoder()), enc, nil
+	}
+	return nil, "", fmt.Errorf("unsupported encoding %q", enc)
+}
+
+// detectEncoding guesses the encoding of a sample without a byte order mark. Text in
+// 8-bit encodings seldom forms valid UTF-8 sequences, so a sample with more valid
+// multi-byte sequences than invalid bytes is taken for UTF-8 with a few broken rows.
+func detectEncoding(sample []byte) Encoding {
+	valid, invalid := 0, 0
+	for rest := sample; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		switch {
+		case r == utf8.RuneError && size == 1 && !utf8.FullRune(rest):
+			size = len(rest) // a sequence cut off by the end of the sample
+		case r == utf8.RuneError && size == 1:
+			invalid++
+		case size > 1:
+			valid++
+		}
+		rest = rest[size:]
+	}
+	if invalid == 0 || valid > invalid {
+		return EncodingUTF8
+	}
+
+	return detect8Bit(sample)
+}
+
+// detect8Bit tells Windows-1250 from ISO-8859-1 by the bytes they decode differently,
+// weighed with windows1250Evidence. Without evidence either way ISO-8859-1 is assumed.
+func detect8Bit(sample []byte) Encoding {
+	score := 0
+	for _, b := range sample {
+		score += windows1250Evidence[b]
+	}
+	if score > 0 {
+		return EncodingWindows1250
+	}
+	return EncodingISO88591
+}
+
+// isASCII reports whether sample holds only 7-bit bytes.
+func isASCII(sample []byte) bool {
+	for _, b := range sample {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// sampleNonASCII reads r up to its first non-ASCII byte and returns the sample starting there,
+// or nil when the input is ASCII throughout.
+func sampleNonASCII(r *bufio.Reader) ([]byte, error) {
+	for {
+		chunk, err := r.Peek(r.Buffered())
+		if err != nil {
+			return nil, err
+		}
+		if i := bytes.IndexFunc(chunk, func(c rune) bool { return c >= utf8.RuneSelf }); i >= 0 {
+			r.Discard(i)
+			sample, err := r.Peek(encodingSampleSize)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			return sample, nil
+		}
+		r.Discard(len(chunk))
+		if _, err := r.Peek(1); errors.Is(err, io.EOF) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/mroczekDNF/swift-api/internal/countries"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
+)
+
+// CSVHeader is the header row of the SWIFT directory file read by ParseSwiftCodes.
+var CSVHeader = []string{
+	ColumnCountryISO2: "COUNTRY ISO2 CODE",
+	ColumnSwiftCode:   "SWIFT CODE",
+	CodeType:          "CODE TYPE",
+	ColumnBankName:    "NAME",
+	ColumnAddress:     "ADDRESS",
+	ColumnTownName:    "TOWN NAME",
+	ColumnCountryName: "COUNTRY NAME",
+	ColumnTimeZone:    "TIME ZONE",
+}
+
+// CSVRecord formats a SWIFT code as a row of the directory file, so that exported
+// files can be imported again by ParseSwiftCodes.
+func CSVRecord(code models.SwiftCode) []string {
+	// Rows added before the code type was stored have none, which the parser would reject.
+	codeType := code.CodeType
+	if codeType == "" {
+		codeType = bic.CodeType(code.SwiftCode)
+	}
+
+	record := make([]string, len(CSVHeader))
+	record[ColumnCountryISO2] = code.CountryISO2
+	record[ColumnSwiftCode] = code.SwiftCode
+	record[CodeType] = codeType
+	record[ColumnBankName] = code.BankName
+	record[ColumnAddress] = code.Address
+	record[ColumnTownName] = code.TownName
+	record[ColumnCountryName] = countries.CanonicalName(code.CountryISO2, code.CountryName)
+	record[ColumnTimeZone] = code.TimeZone
+	return record
+}
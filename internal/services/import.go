@@ -0,0 +1,42 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+)
+
+// ImportSwiftCodes inserts new and updates changed SWIFT codes from an uploaded file.
+// Unlike a sync, codes missing from the file are kept. With dryRun the changes are
+// only planned and the table is left untouched. Both the parsed file and the stored records
+// are held in memory for the diff; see StreamSwiftCodes for the initial load instead.
+func ImportSwiftCodes(db *sql.DB, swiftCodes []models.SwiftCode, dryRun bool) (SyncPlan, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return SyncPlan{}, err
+	}
+	defer tx.Rollback()
+
+	existing, err := loadStoredSwiftCodes(tx)
+	if err != nil {
+		log.Printf("Error loading stored SWIFT codes: %v", err)
+		return SyncPlan{}, err
+	}
+
+	plan := DiffSwiftCodes(existing, swiftCodes)
+	plan.ToRemove = nil
+	if dryRun {
+		return plan, nil
+	}
+
+	if err := applySyncPlan(tx, plan); err != nil {
+		return SyncPlan{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return SyncPlan{}, err
+	}
+
+	log.Printf("SWIFT codes imported: %d added, %d changed, %d unchanged", len(plan.ToInsert), len(plan.ToUpdate), plan.Unchanged)
+	return plan, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+)
+
+// Reason codes of rejected records.
+const (
+	ReasonInsufficientData    = "INSUFFICIENT_DATA"
+	ReasonInvalidEncoding     = "INVALID_ENCODING"
+	ReasonMissingCodeType     = "MISSING_CODE_TYPE"
+	ReasonInvalidCountryCode  = "INVALID_COUNTRY_CODE"
+	ReasonInvalidSwiftCode    = "INVALID_SWIFT_CODE"
+	ReasonCountryMismatch     = "COUNTRY_MISMATCH"
+	ReasonCountryNameMismatch = "COUNTRY_NAME_MISMATCH"
+	ReasonMissingBankName     = "MISSING_BANK_NAME"
+)
+
+// ParseReport describes the outcome of parsing a SWIFT directory file.
+type ParseReport struct {
+	Total      int               `json:"total"`      // Records after the header
+	Accepted   int               `json:"accepted"`   // Records that passed validation, duplicates included
+	Rejected   []RejectedRecord  `json:"rejected"`   // Records that failed validation
+	Duplicates []DuplicateRecord `json:"duplicates"` // Accepted records repeating an earlier SWIFT code
+	Discarded  []string          `json:"discarded"`  // SWIFT codes left out because of conflicting duplicates, see DuplicateReject
+
+	// Replacements holds, under DuplicateLastWins, the last occurrences to be stored instead of
+	// the first ones. StreamSwiftCodes hands only first occurrences to its sink.
+	Replacements []models.SwiftCode `json:"-"`
+}
+
+// RejectedRecord is a CSV row that failed validation.
+type RejectedRecord struct {
+	Line       int      `json:"line"`       // Line of the row in the file; the header is line 1
+	SwiftCode  string   `json:"swiftCode"`  // SWIFT code as found in the row, possibly empty
+	ReasonCode string   `json:"reasonCode"` // One of the Reason constants
+	Reason     string   `json:"reason"`     // Human-readable description
+	Fields     []string `json:"-"`          // The row as read
+}
+
+// DuplicateRecord is a valid CSV row whose SWIFT code already appeared earlier in the file.
+type DuplicateRecord struct {
+	Line      int    `json:"line"`
+	FirstLine int    `json:"firstLine"`
+	SwiftCode string `json:"swiftCode"`
+	Kind      string `json:"kind"` // DuplicateIdentical or DuplicateConflicting
+}
+
+// RejectedByReason counts the rejected records per reason code.
+func (r ParseReport) RejectedByReason() map[string]int {
+	counts := make(map[string]int)
+	for _, rejected := range r.Rejected {
+		counts[rejected.ReasonCode]++
+	}
+	return counts
+}
+
+// Summary describes the report in one line, e.g. for the startup log.
+func (r ParseReport) Summary() string {
+	summary := fmt.Sprintf("%d records: %d accepted, %d rejected", r.Total, r.Accepted, len(r.Rejected))
+
+	counts := r.RejectedByReason()
+	if len(counts) > 0 {
+		reasons := make([]string, 0, len(counts))
+		for reason, count := range counts {
+			reasons = append(reasons, reason+": "+strconv.Itoa(count))
+		}
+		sort.Strings(reasons)
+		summary += " (" + strings.Join(reasons, ", ") + ")"
+	}
+	summary += fmt.Sprintf(", %d duplicates (%d conflicting)", len(r.Duplicates), r.conflictingDuplicates())
+	if len(r.Discarded) > 0 {
+		summary += fmt.Sprintf(", %d SWIFT codes discarded", len(r.Discarded))
+	}
+	return summary
+}
+
+func (r ParseReport) conflictingDuplicates() int {
+	count := 0
+	for _, duplicate := range r.Duplicates {
+		if duplicate.Kind == DuplicateConflicting {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteRejects writes the rejected records as CSV: the file line, the reason code and
+// description, followed by the fields of the original row.
+func (r ParseReport) WriteRejects(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"LINE", "REASON CODE", "REASON"}); err != nil {
+		return err
+	}
+	for _, rejected := range r.Rejected {
+		record := append([]string{strconv.Itoa(rejected.Line), rejected.ReasonCode, rejected.Reason}, rejected.Fields...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
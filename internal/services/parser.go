@@ -4,37 +4,37 @@ import (
 	"encoding/csv"
 	"errors"
 	"io"
-	"log"
 	"os"
-	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/mroczekDNF/swift-api/internal/countries"
 	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/pkg/bic"
+	"golang.org/x/text/unicode/norm"
 )
 
+// Positions of the fields in the canonical record layout, which is also the column order
+// of the SWIFT directory file. Input files are mapped to it by header name, see ColumnMapping.
 const (
-	ColumnCountryISO2  = 0
-	ColumnSwiftCode    = 1
-	ColumnBankName     = 3
-	ColumnAddress      = 4
-	ColumnCountryName  = 6
-	CodeType           = 2
-	headquartersSuffix = "XXX"
+	ColumnCountryISO2 = 0
+	ColumnSwiftCode   = 1
+	ColumnBankName    = 3
+	ColumnAddress     = 4
+	ColumnTownName    = 5
+	ColumnCountryName = 6
+	ColumnTimeZone    = 7
+	CodeType          = 2
 )
 
-// Precompiled regular expressions
-var (
-	// SWIFT: 4 letters, 2 letters, 2 alphanumeric characters, and optionally 3 alphanumeric characters.
-	swiftCodeRegex = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
-	// Country ISO2: exactly 2 uppercase letters.
-	countryISO2Regex = regexp.MustCompile(`^[A-Z]{2}$`)
-)
-
-// isValidRecord checks if the record contains the required data and validates its format.
-func isValidRecord(record []string) bool {
-	if len(record) < 7 {
-		log.Println("Rejected record: insufficient data")
-		return false
+// checkRecord validates a record. For an invalid record it returns the reason code
+// and a description; a valid record yields two empty strings.
+func checkRecord(record []string) (string, string) {
+	// Invalid UTF-8, or bytes undefined in the declared encoding, which decode to U+FFFD.
+	for position, value := range record {
+		if !utf8.ValidString(value) || strings.ContainsRune(value, utf8.RuneError) {
+			return ReasonInvalidEncoding, "invalid byte sequence in " + string(fieldAt(position))
+		}
 	}
 
 	// Normalize data
@@ -44,157 +44,191 @@ func isValidRecord(record []string) bool {
 	codeType := strings.TrimSpace(record[CodeType])
 
 	if codeType == "" {
-		log.Println("Rejected record:", swiftCode, "- missing SWIFT code type")
-		return false
+		return ReasonMissingCodeType, "missing SWIFT code type"
 	}
 
-	// Validate the country code using regex.
-	if !countryISO2Regex.MatchString(countryISO2) {
-		log.Println("Rejected record:", swiftCode, "- invalid country code:", countryISO2)
-		return false
+	// Validate the country code against ISO 3166.
+	country, ok := countries.Lookup(countryISO2)
+	if !ok {
+		return ReasonInvalidCountryCode, "invalid country code: " + countryISO2
 	}
 
-	if len(swiftCode) < 8 || len(swiftCode) > 11 {
-		log.Println("Rejected record:", swiftCode, "- invalid SWIFT code length")
-		return false
-	}
-	// Validate the SWIFT code format using regex.
-	if !swiftCodeRegex.MatchString(swiftCode) {
-		log.Println("Rejected record:", swiftCode, "- invalid SWIFT code format")
-		return false
+	// Validate the SWIFT code format.
+	code, err := bic.Parse(swiftCode)
+	if err != nil {
+		return ReasonInvalidSwiftCode, err.Error()
 	}
 
-	if bankName == "" {
-		log.Println("Rejected record:", swiftCode, "- missing bank name")
-		return false
+	if code.CountryCode() != countryISO2 {
+		return ReasonCountryMismatch, "SWIFT code country segment does not match country code: " + countryISO2
 	}
-	return true
-}
 
-// filterValidRecords filters valid records from the raw data.
-func filterValidRecords(data [][]string) [][]string {
-	validRecords := make([][]string, 0, len(data))
-	for _, record := range data {
-		if isValidRecord(record) {
-			validRecords = append(validRecords, record)
-		}
+	if countryName := record[ColumnCountryName]; !country.MatchesName(countryName) {
+		return ReasonCountryNameMismatch, "country name does not match " + countryISO2 + ": " + countryName
 	}
-	return validRecords
-}
 
-// readCSV reads data from a CSV file and returns a list of records.
-func readCSV(filePath string) ([][]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+	if bankName == "" {
+		return ReasonMissingBankName, "missing bank name"
 	}
-	defer file.Close()
+	return "", ""
+}
 
-	reader := csv.NewReader(file)
+// readCSVFrom reads the records following the header row, numbered in file order, together with
+// the layout of the file. Columns are located by their header names using mapping and records are
+// returned in the canonical layout of the Column constants. Rows may differ in length; see checkWidth.
+func readCSVFrom(r io.Reader, mapping ColumnMapping) ([]streamJob, columnLayout, error) {
+	reader := csv.NewReader(r)
 	reader.Comma = ','
+	reader.FieldsPerRecord = -1 // mis-sized rows are rejected one by one
 
 	// Read headers.
 	headers, err := reader.Read()
 	if err != nil {
-		return nil, err
+		return nil, columnLayout{}, err
 	}
-	if len(headers) == 0 {
-		return [][]string{}, nil
+
+	layout, err := resolveColumns(headers, mapping)
+	if err != nil {
+		return nil, columnLayout{}, err
 	}
 
-	var records [][]string
+	var records []streamJob
 	for {
 		record, err := reader.Read()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return nil, err
+			return nil, columnLayout{}, err
 		}
-		records = append(records, record)
+		line, _ := reader.FieldPos(0)
+		records = append(records, streamJob{seq: len(records), line: line, fields: len(record), record: layout.canonical(record)})
 	}
 
-	return records, nil
-}
-
-// createHeadquartersMap creates a map of headquarters, assigning them unique IDs starting from 1.
-// The key in the map is the first 8 characters of the SWIFT code.
-func createHeadquartersMap(validData [][]string) map[string]int64 {
-	headquartersMap := make(map[string]int64)
-	var idCounter int64 = 1 // IDs start from 1
-
-	for _, record := range validData {
-		swiftCode := strings.ToUpper(strings.TrimSpace(record[ColumnSwiftCode]))
-		// A record is considered a headquarters if the SWIFT code ends with "XXX" and has at least 8 characters.
-		if strings.HasSuffix(swiftCode, headquartersSuffix) && len(swiftCode) >= 8 {
-			key := swiftCode[:8]
-			if _, exists := headquartersMap[key]; !exists {
-				headquartersMap[key] = idCounter
-			}
-		}
-		idCounter++
-	}
-	return headquartersMap
+	return records, layout, nil
 }
 
-// processValidRecords processes valid records, assigning them IDs and establishing relationships
-// between branches and headquarters.
-func processValidRecords(validData [][]string, headquartersMap map[string]int64) []models.SwiftCode {
+// processValidRecords converts valid records into SWIFT code models.
+// Headquarter links are not set here: they are resolved by 8-character prefix
+// against the real database ids once the records have been inserted.
+func processValidRecords(validData [][]string) []models.SwiftCode {
 	// Preallocate slice based on the number of records.
 	swiftCodes := make([]models.SwiftCode, len(validData))
 
 	for idx, record := range validData {
-		swiftCode := strings.ToUpper(strings.TrimSpace(record[ColumnSwiftCode]))
-		isHeadquarter := strings.HasSuffix(swiftCode, headquartersSuffix)
-		countryISO2 := strings.ToUpper(strings.TrimSpace(record[ColumnCountryISO2]))
-
-		// Sprawdź, czy adres jest pusty, i ustaw "UNKNOWN" w takim przypadku
-		address := strings.TrimSpace(record[ColumnAddress])
-		if address == "" {
-			address = "UNKNOWN"
-		}
+		swiftCodes[idx] = swiftCodeFromRecord(record)
+	}
+
+	return swiftCodes
+}
+
+// swiftCodeFromRecord converts a record validated by checkRecord into a SWIFT code model.
+func swiftCodeFromRecord(record []string) models.SwiftCode {
+	swiftCode := bic.MustParse(record[ColumnSwiftCode])
+	countryISO2 := strings.ToUpper(strings.TrimSpace(record[ColumnCountryISO2]))
 
-		swift := models.SwiftCode{
-			ID:            int64(idx), // Temporary value, which may be replaced later.
-			SwiftCode:     swiftCode,
-			BankName:      strings.TrimSpace(record[ColumnBankName]),
-			Address:       address,
-			CountryISO2:   countryISO2,
-			CountryName:   strings.TrimSpace(record[ColumnCountryName]),
-			IsHeadquarter: isHeadquarter,
-			HeadquarterID: nil,
+	// Sprawdź, czy adres jest pusty, i ustaw "UNKNOWN" w takim przypadku
+	address := strings.TrimSpace(record[ColumnAddress])
+	if address == "" {
+		address = "UNKNOWN"
+	}
+
+	// Names and addresses are stored in NFC, so that a letter written with a combining
+	// accent equals its precomposed form.
+	return models.SwiftCode{
+		SwiftCode:     bic.Canonical(record[ColumnSwiftCode]),
+		BankName:      norm.NFC.String(strings.TrimSpace(record[ColumnBankName])),
+		Address:       norm.NFC.String(address),
+		CountryISO2:   countryISO2,
+		CountryName:   countries.CanonicalName(countryISO2, record[ColumnCountryName]),
+		IsHeadquarter: swiftCode.IsPrimaryOffice(),
+		CodeType:      strings.ToUpper(strings.TrimSpace(record[CodeType])),
+		TownName:      norm.NFC.String(strings.TrimSpace(record[ColumnTownName])),
+		TimeZone:      strings.TrimSpace(record[ColumnTimeZone]),
+	}
+}
+
+// rejectedRecord describes a record that failed checkRecord.
+func rejectedRecord(line int, record []string, reasonCode, reason string) RejectedRecord {
+	rejected := RejectedRecord{Line: line, ReasonCode: reasonCode, Reason: reason, Fields: record}
+	if len(record) > ColumnSwiftCode {
+		rejected.SwiftCode = strings.TrimSpace(record[ColumnSwiftCode])
+	}
+	return rejected
+}
+
+// parseRecords validates the records read from a file with layout and converts the valid ones.
+// Repeated SWIFT codes are resolved with policy.
+func parseRecords(records []streamJob, layout columnLayout, policy DuplicatePolicy) ([]models.SwiftCode, ParseReport) {
+	report := ParseReport{
+		Total:    len(records),
+		Rejected: []RejectedRecord{},
+	}
+
+	validData := make([][]string, 0, len(records))
+	validLines := make([]int, 0, len(records))
+	for _, record := range records {
+		reasonCode, reason := layout.checkWidth(record.fields)
+		if reasonCode == "" {
+			reasonCode, reason = checkRecord(record.record)
+		}
+		if reasonCode != "" {
+			report.Rejected = append(report.Rejected, rejectedRecord(record.line, record.record, reasonCode, reason))
+			continue
 		}
+		validData = append(validData, record.record)
+		validLines = append(validLines, record.line)
+	}
+	report.Accepted = len(validData)
 
-		if !isHeadquarter {
-			key := swiftCode[:8]
-			if hqID, exists := headquartersMap[key]; exists {
-				swift.HeadquarterID = &hqID
-			}
+	tracker := newDuplicateTracker()
+	swiftCodes := processValidRecords(validData)
+	firstOccurrences := swiftCodes[:0]
+	for i, code := range swiftCodes {
+		if tracker.track(code, validLines[i]) {
+			firstOccurrences = append(firstOccurrences, code)
 		}
-		swiftCodes[idx] = swift
 	}
+	tracker.resolve(policy, &report)
 
-	return swiftCodes
+	return applyResolution(firstOccurrences, report), report
 }
 
 // ParseSwiftCodes is the main function for parsing SWIFT data from a CSV file.
+// Of repeated SWIFT codes the first occurrence is kept.
 func ParseSwiftCodes(filePath string) ([]models.SwiftCode, error) {
-	data, err := readCSV(filePath)
+	swiftCodes, _, err := ParseSwiftCodesWithReport(filePath, DefaultColumnMapping, DuplicateFirstWins)
+	return swiftCodes, err
+}
+
+// ParseSwiftCodesWithReport parses SWIFT data from a CSV file and reports the rejected and duplicate rows.
+// The encoding of the file is detected, see DecodeInput. A nil mapping means DefaultColumnMapping.
+func ParseSwiftCodesWithReport(filePath string, mapping ColumnMapping, policy DuplicatePolicy) ([]models.SwiftCode, ParseReport, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, err
+		return nil, ParseReport{}, err
 	}
+	defer file.Close()
 
-	if len(data) == 0 {
-		return []models.SwiftCode{}, nil
+	input, _, err := DecodeInput(file, EncodingAuto)
+	if err != nil {
+		return nil, ParseReport{}, err
 	}
+	return ParseSwiftCodesFrom(input, mapping, policy)
+}
 
-	validData := filterValidRecords(data)
-	if len(validData) == 0 {
-		return []models.SwiftCode{}, nil
+// ParseSwiftCodesFrom parses SWIFT data from r, locating the columns by header name with mapping.
+// A header lacking a required column is reported as *MissingColumnsError. r must yield UTF-8;
+// wrap files in other encodings with DecodeInput. Each SWIFT code is returned once; repeated
+// codes are listed in the report and resolved with policy. A nil mapping means DefaultColumnMapping.
+func ParseSwiftCodesFrom(r io.Reader, mapping ColumnMapping, policy DuplicatePolicy) ([]models.SwiftCode, ParseReport, error) {
+	if mapping == nil {
+		mapping = DefaultColumnMapping
 	}
-
-	headquartersMap := createHeadquartersMap(validData)
-	swiftCodes := processValidRecords(validData, headquartersMap)
-
-	return swiftCodes, nil
+	records, layout, err := readCSVFrom(r, mapping)
+	if err != nil {
+		return nil, ParseReport{}, err
+	}
+	swiftCodes, report := parseRecords(records, layout, policy)
+	return swiftCodes, report, nil
 }
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+)
+
+// defaultStreamBuffer is the default number of records in flight in StreamSwiftCodes.
+const defaultStreamBuffer = 4096
+
+// StreamOptions tunes StreamSwiftCodes. Zero values select the defaults.
+type StreamOptions struct {
+	Workers   int // Validation goroutines; GOMAXPROCS by default
+	Buffer    int // Records read but not yet handed to the sink; bounds the memory used
+	BatchSize int // Records passed to the sink at once; insertBatchSize by default
+}
+
+func (o StreamOptions) withDefaults() StreamOptions {
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	if o.Buffer <= 0 {
+		o.Buffer = defaultStreamBuffer
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = insertBatchSize
+	}
+	return o
+}
+
+// streamJob is a record read from the file, numbered in file order.
+type streamJob struct {
+	seq    int
+	line   int
+	fields int      // number of fields in the file row
+	record []string // canonical layout
+}
+
+// streamResult is a validated record; rejected records carry a reason code.
+type streamResult struct {
+	streamJob
+	code       models.SwiftCode
+	reasonCode string
+	reason     string
+}
+
+// StreamSwiftCodes parses SWIFT data from r like ParseSwiftCodesFrom without holding the file in memory.
+// r must yield UTF-8, see DecodeInput.
+// A reader goroutine feeds the records to opts.Workers validation goroutines through bounded
+// channels and the valid records are passed to sink in file order, opts.BatchSize at a time.
+// At most opts.Buffer records are in flight, so a slow sink stops the reading. The sink owns
+// each batch it is given. Apart from the rejected and duplicate records, the only state growing
+// with the file is one small map entry per distinct SWIFT code, kept to detect duplicates.
+//
+// Only the first occurrence of each SWIFT code reaches the sink. Once the file is read, the
+// report tells how policy resolves the repeated codes: Replacements for DuplicateLastWins and
+// Discarded for DuplicateReject are for the caller to apply to what the sink stored.
+//
+// An error from the sink or from reading the file stops the pipeline and is returned
+// together with the report of the records handled so far.
+func StreamSwiftCodes(ctx context.Context, r io.Reader, mapping ColumnMapping, policy DuplicatePolicy, opts StreamOptions,
+	sink func([]models.SwiftCode) error) (ParseReport, error) {
+	opts = opts.withDefaults()
+	if mapping == nil {
+		mapping = DefaultColumnMapping
+	}
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true   // records are copied into the canonical layout
+	reader.FieldsPerRecord = -1 // mis-sized rows are rejected one by one
+
+	header, err := reader.Read()
+	if err != nil {
+		return ParseReport{}, err
+	}
+	layout, err := resolveColumns(header, mapping)
+	if err != nil {
+		return ParseReport{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// A slot is taken for every record read and released once the record has left the
+	// pipeline, so neither the channels nor the reordering below grow beyond opts.Buffer.
+	slots := make(chan struct{}, opts.Buffer)
+	jobs := make(chan streamJob, opts.Buffer)
+	results := make(chan streamResult, opts.Buffer)
+
+	var readErr error
+	go func() {
+		defer close(jobs)
+		for seq := 0; ; seq++ {
+			record, err := reader.Read()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+				}
+				return
+			}
+			line, _ := reader.FieldPos(0)
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			jobs <- streamJob{seq: seq, line: line, fields: len(record), record: layout.canonical(record)}
+		}
+	}()
+
+	var workers sync.WaitGroup
+	for range opts.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				result := streamResult{streamJob: job}
+				result.reasonCode, result.reason = layout.checkWidth(job.fields)
+				if result.reasonCode == "" {
+					result.reasonCode, result.reason = checkRecord(job.record)
+				}
+				if result.reasonCode == "" {
+					result.code = swiftCodeFromRecord(job.record)
+				}
+				results <- result
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	report := ParseReport{Rejected: []RejectedRecord{}}
+	tracker := newDuplicateTracker()
+	pending := make(map[int]streamResult)
+	batch := make([]models.SwiftCode, 0, opts.BatchSize)
+	next := 0
+
+	var sinkErr error
+	for result := range results {
+		if sinkErr != nil {
+			continue // drain, so that the goroutines stop before returning
+		}
+
+		// Workers finish out of order; hand the records on in file order.
+		pending[result.seq] = result
+		for sinkErr == nil {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+
+			report.Total++
+			if result.reasonCode != "" {
+				report.Rejected = append(report.Rejected, rejectedRecord(result.line, result.record, result.reasonCode, result.reason))
+				continue
+			}
+
+			report.Accepted++
+			if !tracker.track(result.code, result.line) {
+				continue
+			}
+
+			batch = append(batch, result.code)
+			if len(batch) == opts.BatchSize {
+				if sinkErr = sink(batch); sinkErr != nil {
+					cancel()
+				}
+				batch = make([]models.SwiftCode, 0, opts.BatchSize)
+			}
+		}
+	}
+
+	tracker.resolve(policy, &report)
+	if sinkErr != nil {
+		return report, sinkErr
+	}
+	if readErr != nil {
+		return report, readErr
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if len(batch) > 0 {
+		if err := sink(batch); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// swiftCodeKey packs an 11-character SWIFT code into an integer, reading it as a base-36
+// number, so that duplicate detection does not keep a string per code.
+func swiftCodeKey(swiftCode string) uint64 {
+	var key uint64
+	for i := 0; i < len(swiftCode); i++ {
+		c := swiftCode[i]
+		if c >= 'A' {
+			c = c - 'A' + 10
+		} else {
+			c -= '0'
+		}
+		key = key*36 + uint64(c)
+	}
+	return key
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+)
+
+// SyncResult reports how many records a synchronisation added, changed and removed.
+type SyncResult struct {
+	Added     int
+	Changed   int
+	Removed   int
+	Unchanged int
+}
+
+// SyncPlan lists the changes needed to bring the table in line with a parsed file.
+type SyncPlan struct {
+	ToInsert  []models.SwiftCode
+	ToUpdate  []models.SwiftCode
+	ToRemove  []string
+	Unchanged int
+}
+
+// Result returns the counts described by the plan.
+func (p SyncPlan) Result() SyncResult {
+	return SyncResult{
+		Added:     len(p.ToInsert),
+		Changed:   len(p.ToUpdate),
+		Removed:   len(p.ToRemove),
+		Unchanged: p.Unchanged,
+	}
+}
+
+// DiffSwiftCodes compares the stored records with the parsed ones, matching them by SWIFT code.
+// Headquarter links are not compared, they are recomputed after every sync.
+func DiffSwiftCodes(existing, parsed []models.SwiftCode) SyncPlan {
+	stored := make(map[string]models.SwiftCode, len(existing))
+	for _, code := range existing {
+		stored[code.SwiftCode] = code
+	}
+
+	var plan SyncPlan
+	seen := make(map[string]bool, len(parsed))
+	for _, code := range parsed {
+		if seen[code.SwiftCode] {
+			continue
+		}
+		seen[code.SwiftCode] = true
+
+		current, exists := stored[code.SwiftCode]
+		switch {
+		case !exists:
+			plan.ToInsert = append(plan.ToInsert, code)
+		case !sameSwiftCodeData(current, code):
+			plan.ToUpdate = append(plan.ToUpdate, code)
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for _, code := range existing {
+		if !seen[code.SwiftCode] {
+			plan.ToRemove = append(plan.ToRemove, code.SwiftCode)
+		}
+	}
+	return plan
+}
+
+// sameSwiftCodeData reports whether two records carry the same data, ignoring ids and links.
+func sameSwiftCodeData(a, b models.SwiftCode) bool {
+	return a.BankName == b.BankName &&
+		a.Address == b.Address &&
+		a.CountryISO2 == b.CountryISO2 &&
+		a.CountryName == b.CountryName &&
+		a.IsHeadquarter == b.IsHeadquarter &&
+		a.CodeType == b.CodeType &&
+		a.TownName == b.TownName &&
+		a.TimeZone == b.TimeZone
+}
+
+// SyncSwiftCodesToDatabase applies a parsed SWIFT file to an already populated table:
+// new codes are inserted, modified ones updated and codes missing from the file removed.
+// All changes, including recomputed headquarter links, are applied in one transaction.
+// Like ImportSwiftCodes it holds the whole file and every stored record in memory.
+func SyncSwiftCodesToDatabase(db *sql.DB, swiftCodes []models.SwiftCode) (SyncResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	defer tx.Rollback()
+
+	existing, err := loadStoredSwiftCodes(tx)
+	if err != nil {
+		log.Printf("Error loading stored SWIFT codes: %v", err)
+		return SyncResult{}, err
+	}
+
+	plan := DiffSwiftCodes(existing, swiftCodes)
+	if err := applySyncPlan(tx, plan); err != nil {
+		return SyncResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return SyncResult{}, err
+	}
+
+	result := plan.Result()
+	log.Printf("SWIFT codes synchronised: %d added, %d changed, %d removed, %d unchanged",
+		result.Added, result.Changed, result.Removed, result.Unchanged)
+	return result, nil
+}
+
+// loadStoredSwiftCodes reads the comparable columns of every stored record.
+func loadStoredSwiftCodes(tx *sql.Tx) ([]models.SwiftCode, error) {
+	rows, err := tx.Query("SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone FROM swift_codes;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var swiftCodes []models.SwiftCode
+	for rows.Next() {
+		var code models.SwiftCode
+		var address, codeType, townName, timeZone sql.NullString
+		if err := rows.Scan(&code.SwiftCode, &code.BankName, &address, &code.CountryISO2, &code.CountryName, &code.IsHeadquarter,
+			&codeType, &townName, &timeZone); err != nil {
+			return nil, err
+		}
+		code.Address = "UNKNOWN"
+		if address.Valid {
+			code.Address = address.String
+		}
+		code.CodeType = codeType.String
+		code.TownName = townName.String
+		code.TimeZone = timeZone.String
+		swiftCodes = append(swiftCodes, code)
+	}
+	return swiftCodes, rows.Err()
+}
+
+// applySyncPlan executes the plan and recomputes headquarter links.
+func applySyncPlan(tx *sql.Tx, plan SyncPlan) error {
+	for _, code := range plan.ToRemove {
+		if _, err := tx.Exec("DELETE FROM swift_codes WHERE swift_code = $1;", code); err != nil {
+			log.Printf("Error removing SWIFT code %s: %v", code, err)
+			return err
+		}
+	}
+
+	upsert := `INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (swift_code) DO UPDATE SET
+		bank_name = EXCLUDED.bank_name,
+		address = EXCLUDED.address,
+		country_iso2 = EXCLUDED.country_iso2,
+		country_name = EXCLUDED.country_name,
+		is_headquarter = EXCLUDED.is_headquarter,
+		code_type = EXCLUDED.code_type,
+		town_name = EXCLUDED.town_name,
+		time_zone = EXCLUDED.time_zone;`
+
+	for _, batch := range [][]models.SwiftCode{plan.ToInsert, plan.ToUpdate} {
+		for _, code := range batch {
+			if _, err := tx.Exec(upsert, swiftCodeValues(code)...); err != nil {
+				log.Printf("Error upserting SWIFT code %s: %v", code.SwiftCode, err)
+				return err
+			}
+		}
+	}
+
+	return relinkHeadquarters(tx)
+}
+
+// relinkHeadquarters recomputes every headquarter link from the SWIFT code prefixes.
+func relinkHeadquarters(tx *sql.Tx) error {
+	for _, query := range headquarterLinkQueries {
+		if _, err := tx.Exec(query); err != nil {
+			log.Printf("Error relinking branches to headquarters: %v", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package bic parses and validates Business Identifier Codes (SWIFT codes, ISO 9362).
+//
+// A BIC is 8 or 11 characters long:
+//
+//	BANK    institution code (4 letters)
+//	  US    country code (2 letters)
+//	    33  location code (2 letters or digits)
+//	   XXX  optional branch code (3 letters or digits, "XXX" for the primary office)
+package bic
+
+import (
+	"errors"
+	"strings"
+)
+
+// PrimaryOfficeBranchCode is the branch code of an institution's primary office (headquarter).
+const PrimaryOfficeBranchCode = "XXX"
+
+// Code types of the CODE TYPE column of the SWIFT directory.
+const (
+	CodeTypeBIC8  = "BIC8"
+	CodeTypeBIC11 = "BIC11"
+)
+
+// Errors wrapped by ParseError, usable with errors.Is.
+var (
+	ErrInvalidLength          = errors.New("BIC must be 8 or 11 characters long")
+	ErrInvalidInstitutionCode = errors.New("institution code must be 4 letters")
+	ErrInvalidCountryCode     = errors.New("country code must be 2 letters")
+	ErrInvalidLocationCode    = errors.New("location code must be 2 letters or digits")
+	ErrInvalidBranchCode      = errors.New("branch code must be 3 letters or digits")
+)
+
+// CodeType returns the code type matching the length of code, for records that do not state one.
+// The code does not need to be a valid BIC.
+func CodeType(code string) string {
+	if len(strings.TrimSpace(code)) == 8 {
+		return CodeTypeBIC8
+	}
+	return CodeTypeBIC11
+}
+
+// Canonical returns the form in which SWIFT codes are stored and compared: the 11-character
+// form of a valid BIC, so that a BIC8 and its BIC11 name the same record. Other input is
+// returned upper-cased and trimmed, so that legacy codes still match verbatim.
+func Canonical(code string) string {
+	if b, err := Parse(code); err == nil {
+		return b.BIC11()
+	}
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ParseError reports why a string is not a valid BIC.
+type ParseError struct {
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return "invalid BIC " + `"` + e.Input + `"` + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// BIC is a validated Business Identifier Code in its 8 or 11 character form.
+type BIC struct {
+	code string
+}
+
+// Parse validates s and returns the BIC. Surrounding whitespace is ignored and letters are upper-cased.
+func Parse(s string) (BIC, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+
+	var err error
+	switch {
+	case len(code) != 8 && len(code) != 11:
+		err = ErrInvalidLength
+	case !isLetters(code[0:4]):
+		err = ErrInvalidInstitutionCode
+	case !isLetters(code[4:6]):
+		err = ErrInvalidCountryCode
+	case !isAlphanumeric(code[6:8]):
+		err = ErrInvalidLocationCode
+	case len(code) == 11 && !isAlphanumeric(code[8:11]):
+		err = ErrInvalidBranchCode
+	}
+	if err != nil {
+		return BIC{}, &ParseError{Input: s, Err: err}
+	}
+	return BIC{code: code}, nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is intended for already validated input.
+func MustParse(s string) BIC {
+	b, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// String returns the BIC as parsed, in its 8 or 11 character form.
+func (b BIC) String() string {
+	return b.code
+}
+
+// InstitutionCode returns the 4-letter institution (bank) code.
+func (b BIC) InstitutionCode() string {
+	return b.code[0:4]
+}
+
+// CountryCode returns the 2-letter ISO 3166 country code.
+func (b BIC) CountryCode() string {
+	return b.code[4:6]
+}
+
+// LocationCode returns the 2-character location code.
+func (b BIC) LocationCode() string {
+	return b.code[6:8]
+}
+
+// BranchCode returns the 3-character branch code; a BIC8 denotes the primary office "XXX".
+func (b BIC) BranchCode() string {
+	if len(b.code) == 8 {
+		return PrimaryOfficeBranchCode
+	}
+	return b.code[8:11]
+}
+
+// BIC8 returns the 8-character form identifying the institution at its location.
+func (b BIC) BIC8() string {
+	return b.code[0:8]
+}
+
+// BIC11 returns the 11-character form, completing a BIC8 with the primary office branch code.
+func (b BIC) BIC11() string {
+	return b.BIC8() + b.BranchCode()
+}
+
+// IsBIC8 reports whether the BIC was given in its 8-character form.
+func (b BIC) IsBIC8() bool {
+	return len(b.code) == 8
+}
+
+// IsPrimaryOffice reports whether the BIC identifies the institution's primary office (headquarter).
+func (b BIC) IsPrimaryOffice() bool {
+	return b.BranchCode() == PrimaryOfficeBranchCode
+}
+
+// PrimaryOffice returns the BIC11 of the primary office this BIC belongs to.
+func (b BIC) PrimaryOffice() BIC {
+	return BIC{code: b.BIC8() + PrimaryOfficeBranchCode}
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'A' || s[i] > 'Z') && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/services"
+)
+
+const benchmarkRecords = 20000
+
+func setupBenchmarkDatabase(b *testing.B) []models.SwiftCode {
+	db.InitDatabase(testDBURL)
+	b.Cleanup(db.CloseDatabase)
+	db.MigrateDatabase()
+
+	swiftCodes := make([]models.SwiftCode, benchmarkRecords)
+	for i := range swiftCodes {
+		swiftCodes[i] = models.SwiftCode{
+			SwiftCode:   fmt.Sprintf("BNCHPL%05d", i),
+			BankName:    "Benchmark Bank",
+			Address:     "Benchmark Street",
+			CountryISO2: "PL",
+			CountryName: "POLAND",
+		}
+	}
+	return swiftCodes
+}
+
+func truncateSwiftCodes(b *testing.B) {
+	if _, err := db.DB.Exec("TRUNCATE TABLE swift_codes RESTART IDENTITY CASCADE;"); err != nil {
+		b.Fatalf("Error truncating table: %v", err)
+	}
+}
+
+// saveRowByRow is the previous loader: one INSERT round-trip per record, without a transaction.
+func saveRowByRow(conn *sql.DB, swiftCodes []models.SwiftCode) error {
+	query := `INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`
+	for _, code := range swiftCodes {
+		var id int64
+		if err := conn.QueryRow(query, code.SwiftCode, code.BankName, code.Address, code.CountryISO2, code.CountryName, code.IsHeadquarter, code.HeadquarterID).Scan(&id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func BenchmarkSaveSwiftCodes_Copy(b *testing.B) {
+	swiftCodes := setupBenchmarkDatabase(b)
+	b.Cleanup(func() { truncateSwiftCodes(b) })
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		truncateSwiftCodes(b)
+		b.StartTimer()
+
+		if err := services.SaveSwiftCodesToDatabase(db.DB, swiftCodes); err != nil {
+			b.Fatalf("Error saving SWIFT codes: %v", err)
+		}
+	}
+	b.ReportMetric(float64(benchmarkRecords*b.N)/b.Elapsed().Seconds(), "rows/s")
+}
+
+func BenchmarkSaveSwiftCodes_RowByRow(b *testing.B) {
+	swiftCodes := setupBenchmarkDatabase(b)
+	b.Cleanup(func() { truncateSwiftCodes(b) })
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		truncateSwiftCodes(b)
+		b.StartTimer()
+
+		if err := saveRowByRow(db.DB, swiftCodes); err != nil {
+			b.Fatalf("Error saving SWIFT codes: %v", err)
+		}
+	}
+	b.ReportMetric(float64(benchmarkRecords*b.N)/b.Elapsed().Seconds(), "rows/s")
+}
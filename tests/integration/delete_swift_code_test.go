@@ -29,6 +29,7 @@ func TestDeleteSwiftCode_Success_HQ(t *testing.T) {
 	swiftCode := "BANKDE44XXX" // Deutsche Bank HQ (Germany)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -63,6 +64,7 @@ func TestDeleteSwiftCode_HQWithBranches(t *testing.T) {
 	assert.NotNil(t, branch2HQID, "Expected BANKUS33DEF to have a headquarter_id before deletion")
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/BANKUS33XXX", nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -96,6 +98,7 @@ func TestDeleteSwiftCode_Success_Branch(t *testing.T) {
 	swiftCode := "BANKCA77AAA" // Canadian Bank Branch A (Canada)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -122,6 +125,7 @@ func TestDeleteSwiftCode_NotFound(t *testing.T) {
 	swiftCode := "BANKXX99ZZZ"
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -130,5 +134,6 @@ func TestDeleteSwiftCode_NotFound(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "SWIFT code not found", response["message"])
+	assert.Equal(t, "SWIFT_NOT_FOUND", response["code"])
+	assert.Equal(t, "SWIFT code not found", response["detail"])
 }
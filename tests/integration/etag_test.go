@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/routes"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendWithHeader(router *gin.Engine, method, path, header, value string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestETag_LostUpdateIsRefused - of two edits based on the same ETag only the first is applied
+func TestETag_LostUpdateIsRefused(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter(db.DB, time.Hour)
+
+	read := sendWithHeader(router, "GET", "/v1/swift-codes/BANKUS33ABC", "", "", nil)
+	assert.Equal(t, http.StatusOK, read.Code)
+	etag := read.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	cached := sendWithHeader(router, "GET", "/v1/swift-codes/BANKUS33ABC", "If-None-Match", etag, nil)
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+
+	first := sendWithHeader(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", "If-Match", etag, gin.H{"address": "1 First Street"})
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	second := sendWithHeader(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", "If-Match", etag, gin.H{"address": "2 Second Street"})
+	assert.Equal(t, http.StatusPreconditionFailed, second.Code)
+
+	missing := sendWithHeader(router, "DELETE", "/v1/swift-codes/BANKUS33ABC", "", "", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, missing.Code)
+
+	reread := sendWithHeader(router, "GET", "/v1/swift-codes/BANKUS33ABC", "If-None-Match", etag, nil)
+	assert.Equal(t, http.StatusOK, reread.Code)
+	assert.NotEqual(t, etag, reread.Header().Get("ETag"))
+	assert.Equal(t, first.Header().Get("ETag"), reread.Header().Get("ETag"), "An update returns the ETag of the updated record")
+	assert.Contains(t, reread.Body.String(), "1 First Street")
+}
+
+// TestETag_HeadquarterFollowsBranchChanges - editing a branch changes its headquarter's ETag
+func TestETag_HeadquarterFollowsBranchChanges(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter(db.DB, time.Hour)
+
+	headquarterETag := sendWithHeader(router, "GET", "/v1/swift-codes/BANKUS33XXX", "", "", nil).Header().Get("ETag")
+
+	update := sendWithHeader(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", "If-Match", "*", gin.H{"bankName": "Renamed Branch"})
+	assert.Equal(t, http.StatusOK, update.Code)
+
+	reread := sendWithHeader(router, "GET", "/v1/swift-codes/BANKUS33XXX", "If-None-Match", headquarterETag, nil)
+	assert.Equal(t, http.StatusOK, reread.Code)
+	assert.Contains(t, reread.Body.String(), "Renamed Branch")
+}
@@ -0,0 +1,42 @@
+package integration
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportSwiftCodes_CSVByCountry(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.GET("/v1/swift-codes/export", handler.ExportSwiftCodes)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/export?country=US", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	records, err := csv.NewReader(strings.NewReader(recorder.Body.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, "COUNTRY ISO2 CODE", records[0][0])
+	assert.Greater(t, len(records), 1)
+	for _, record := range records[1:] {
+		assert.Equal(t, "US", record[0])
+		assert.Equal(t, "UNITED STATES", record[6])
+	}
+}
@@ -38,7 +38,7 @@ func TestGetSwiftCodesByCountry_Found(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, "US", response["countryISO2"])
-	assert.Equal(t, "United States", response["countryName"])
+	assert.Equal(t, "UNITED STATES", response["countryName"])
 
 	swiftCodes, swiftCodesExist := response["swiftCodes"].([]interface{})
 	assert.True(t, swiftCodesExist, "The 'swiftCodes' field should exist")
@@ -67,14 +67,14 @@ func TestGetSwiftCodesByCountry_NotFound(t *testing.T) {
 
 	log.Printf("JSON Response: %s", recorder.Body.String())
 
-	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "Expected HTTP 500 due to database error handling")
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "Expected HTTP 404 for a country without SWIFT codes")
 
 	var response map[string]interface{}
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err, "Expected JSON response to be parsable")
 
-	assert.Contains(t, response, "error", "Expected 'error' field in the response")
-	assert.Equal(t, "Error fetching SWIFT codes", response["error"], "Expected database error message")
+	assert.Equal(t, "COUNTRY_NOT_FOUND", response["code"], "Expected a stable error code in the response")
+	assert.Equal(t, "No SWIFT codes found for the given country", response["detail"], "Expected not found message")
 }
 
 func TestGetSwiftCodesByCountry_InvalidISO2(t *testing.T) {
@@ -94,12 +94,12 @@ func TestGetSwiftCodesByCountry_InvalidISO2(t *testing.T) {
 
 	log.Printf("JSON Response: %s", recorder.Body.String())
 
-	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "Expected HTTP 500 due to database error handling")
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "Expected HTTP 404 for a country without SWIFT codes")
 
 	var response map[string]interface{}
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err, "Expected JSON response to be parsable")
 
-	assert.Contains(t, response, "error", "Expected 'error' field in the response")
-	assert.Equal(t, "Error fetching SWIFT codes", response["error"], "Expected database error message")
+	assert.Equal(t, "COUNTRY_NOT_FOUND", response["code"], "Expected a stable error code in the response")
+	assert.Equal(t, "No SWIFT codes found for the given country", response["detail"], "Expected not found message")
 }
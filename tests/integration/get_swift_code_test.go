@@ -42,7 +42,7 @@ func TestGetSwiftCodeDetails_Headquarter(t *testing.T) {
 	assert.Equal(t, "BANKUS33XXX", response["swiftCode"])
 	assert.Equal(t, "Test Bank USA", response["bankName"])
 	assert.Equal(t, "US", response["countryISO2"])
-	assert.Equal(t, "United States", response["countryName"])
+	assert.Equal(t, "UNITED STATES", response["countryName"])
 	assert.True(t, response["isHeadquarter"].(bool))
 
 	branches, branchesExist := response["branches"].([]interface{})
@@ -92,7 +92,7 @@ func TestGetSwiftCodeDetails_HQWithoutBranches(t *testing.T) {
 	assert.Equal(t, "BANKDE44XXX", response["swiftCode"])
 	assert.Equal(t, "Deutsche Bank HQ", response["bankName"])
 	assert.Equal(t, "DE", response["countryISO2"])
-	assert.Equal(t, "Germany", response["countryName"])
+	assert.Equal(t, "GERMANY", response["countryName"])
 	assert.True(t, response["isHeadquarter"].(bool))
 
 	// Verify that 'branches' field exists and is an empty list
@@ -127,8 +127,44 @@ func TestGetSwiftCodeDetails_BranchOnly(t *testing.T) {
 	assert.Equal(t, "BANKGB22AAA", response["swiftCode"])
 	assert.Equal(t, "Independent Branch UK", response["bankName"])
 	assert.Equal(t, "GB", response["countryISO2"])
-	assert.Equal(t, "United Kingdom", response["countryName"])
+	assert.Equal(t, "UNITED KINGDOM", response["countryName"])
 	assert.False(t, response["isHeadquarter"].(bool))
 	_, branchesExist := response["branches"].([]interface{})
 	assert.False(t, branchesExist, "The 'branches' field should not exist for a branch")
 }
+
+func TestGetSwiftCodeDetails_BIC8AndFallback(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.GET("/swift-codes/:swiftCode", handler.GetSwiftCodeDetails)
+
+	req, _ := http.NewRequest("GET", "/swift-codes/bankus33", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "BANKUS33XXX", response["swiftCode"])
+
+	req, _ = http.NewRequest("GET", "/swift-codes/BANKUS33ZZZ?fallback=true", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response = map[string]interface{}{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "BANKUS33XXX", response["swiftCode"])
+	assert.Equal(t, true, response["fallback"])
+	assert.Equal(t, "BANKUS33ZZZ", response["requestedSwiftCode"])
+}
@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/routes"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendWithIdempotencyKey(router *gin.Engine, method, path, key string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestIdempotency_RetriedPostIsReplayed - a retried POST gets the original 200 instead of a 409
+func TestIdempotency_RetriedPostIsReplayed(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter(db.DB, time.Hour)
+
+	body := gin.H{
+		"swiftCode":     "RETRFRPPXXX",
+		"bankName":      "Retry Bank",
+		"countryISO2":   "FR",
+		"countryName":   "France",
+		"isHeadquarter": true,
+	}
+
+	first := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "add-RETRFRPPXXX", body)
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	retry := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "add-RETRFRPPXXX", body)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	// Without the key the same request is a real conflict.
+	again := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "add-RETRFRPPXXX-2", body)
+	assert.Equal(t, http.StatusConflict, again.Code)
+
+	body["bankName"] = "Another Bank"
+	reused := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "add-RETRFRPPXXX", body)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Contains(t, reused.Body.String(), handlers.CodeIdempotencyKeyReused)
+}
+
+// TestIdempotency_ExpiredKeyIsReused - a key older than the window is processed as a new request
+func TestIdempotency_ExpiredKeyIsReused(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter(db.DB, time.Hour)
+
+	first := sendWithIdempotencyKey(router, "DELETE", "/v1/swift-codes/NONEXIS1XXX", "delete-1", nil)
+	assert.Equal(t, http.StatusNotFound, first.Code)
+
+	_, err := db.DB.Exec("UPDATE idempotency_keys SET created_at = now() - interval '2 hours' WHERE key = 'delete-1';")
+	assert.NoError(t, err)
+
+	repo := repositories.NewIdempotencyKeyRepository(db.DB)
+	stored, err := repo.ReserveKey("delete-1", "another request", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored, "An expired key should be reserved again")
+
+	removed, err := repo.DeleteExpiredKeys(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), removed)
+}
+
+// TestIdempotency_LookupIgnoresKey - the lookup changes nothing, so its Idempotency-Key is not stored
+func TestIdempotency_LookupIgnoresKey(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter(db.DB, time.Hour)
+
+	first := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes/lookup", "lookup-1", gin.H{"swiftCodes": []string{"NONEXIS1XXX"}})
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	second := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes/lookup", "lookup-1", gin.H{"swiftCodes": []string{"NONEXIS2XXX"}})
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Empty(t, second.Header().Get(handlers.IdempotentReplayedHeader))
+
+	var stored int
+	assert.NoError(t, db.DB.QueryRow("SELECT COUNT(*) FROM idempotency_keys WHERE key = 'lookup-1';").Scan(&stored))
+	assert.Equal(t, 0, stored)
+}
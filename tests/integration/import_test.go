@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func uploadImport(t *testing.T, query, content string) handlers.ImportResult {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/v1/imports", handlers.NewImportHandler(db.DB).ImportSwiftCodes)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "upload.csv")
+	part.Write([]byte(content))
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/v1/imports"+query, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result handlers.ImportResult
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	return result
+}
+
+func TestImportSwiftCodes_DryRunThenApply(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	content := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,IMPTPLPWXXX,BIC11,Imported Bank,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n" +
+		"PL,IMPTPLPW001,BIC11,Imported Bank,Branch Street 2,WARSZAWA,POLAND,Europe/Warsaw\n" +
+		"PL,IMPTDEPW002,BIC11,Imported Bank,Branch Street 3,WARSZAWA,POLAND,Europe/Warsaw\n"
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+
+	preview := uploadImport(t, "?dryRun=true", content)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, 2, preview.Inserted)
+	assert.Equal(t, 1, preview.Rejected)
+	assert.Equal(t, 4, preview.RejectedRecords[0].Line)
+
+	notYetImported, err := repo.GetBySwiftCode("IMPTPLPWXXX")
+	assert.NoError(t, err)
+	assert.Nil(t, notYetImported, "A dry run must not touch the table")
+
+	applied := uploadImport(t, "", content)
+	assert.False(t, applied.DryRun)
+	assert.Equal(t, 2, applied.Inserted)
+
+	branch, err := repo.GetBySwiftCode("IMPTPLPW001")
+	assert.NoError(t, err)
+	if assert.NotNil(t, branch) {
+		assert.NotNil(t, branch.HeadquarterID, "Imported branches should be linked to their headquarter")
+	}
+
+	existing, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, existing, "Codes missing from the uploaded file must be kept")
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// Links must come from the real ids even when the sequence does not start at 1.
+func TestSaveSwiftCodes_LinksUseDatabaseIDs(t *testing.T) {
+	db.InitDatabase(testDBURL)
+	t.Cleanup(db.CloseDatabase)
+	db.MigrateDatabase()
+	defer CleanupTestDatabase(t)
+
+	_, err := db.DB.Exec("SELECT setval(pg_get_serial_sequence('swift_codes', 'id'), 500);")
+	assert.NoError(t, err)
+
+	records, err := services.ParseSwiftCodes("../../data/test_data.csv")
+	assert.NoError(t, err)
+	assert.NoError(t, services.SaveSwiftCodesToDatabase(db.DB, records))
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	hq, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+
+	for _, code := range []string{"BANKUS33ABC", "BANKUS33DEF"} {
+		branch, err := repo.GetBySwiftCode(code)
+		assert.NoError(t, err)
+		assert.NotNil(t, branch.HeadquarterID)
+		assert.Equal(t, hq.ID, *branch.HeadquarterID)
+	}
+
+	issues, err := repo.FindHeadquarterLinkIssues()
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestGetHeadquarterLinkIssues_ReportsWrongInstitution(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.GET("/v1/integrity/headquarter-links", handler.GetHeadquarterLinkIssues)
+
+	// Corrupt a link the way predicted ids used to: point a US branch at the German headquarter.
+	_, err := db.DB.Exec(`UPDATE swift_codes SET headquarter_id = (SELECT id FROM swift_codes WHERE swift_code = 'BANKDE44XXX')
+		WHERE swift_code = 'BANKUS33ABC';`)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/v1/integrity/headquarter-links", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	issues := response["issues"].([]interface{})
+	assert.Len(t, issues, 1)
+	issue := issues[0].(map[string]interface{})
+	assert.Equal(t, "BANKUS33ABC", issue["swiftCode"])
+	assert.Equal(t, "BANKDE44XXX", issue["linkedSwiftCode"])
+	assert.Equal(t, "BANKUS33XXX", issue["expectedHeadquarter"])
+	assert.Equal(t, "WRONG_INSTITUTION", issue["problem"])
+}
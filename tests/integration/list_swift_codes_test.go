@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSwiftCodes_PaginatesWithCursor(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.GET("/v1/swift-codes", handler.ListSwiftCodes)
+
+	var collected []string
+	link := "/v1/swift-codes?isHeadquarter=false&sort=-bankName&limit=4"
+	for pages := 0; link != "" && pages < 10; pages++ {
+		req, _ := http.NewRequest("GET", link, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+
+		for _, code := range response["swiftCodes"].([]interface{}) {
+			item := code.(map[string]interface{})
+			assert.False(t, item["isHeadquarter"].(bool))
+			collected = append(collected, item["bankName"].(string))
+		}
+
+		link, _ = response["next"].(string)
+	}
+
+	// test_data.csv contains 13 branches; every one is returned exactly once, in descending name order.
+	assert.Len(t, collected, 13)
+	for i := 1; i < len(collected); i++ {
+		assert.GreaterOrEqual(t, collected[i-1], collected[i])
+	}
+}
+
+func TestListSwiftCodes_FilterByCountryNameAndPrefix(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.GET("/v1/swift-codes", handler.ListSwiftCodes)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes?country=in&bankName=state%20bank&prefix=BANKIN8", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	swiftCodes := response["swiftCodes"].([]interface{})
+	assert.Len(t, swiftCodes, 1)
+	assert.Equal(t, "BANKIN88AAA", swiftCodes[0].(map[string]interface{})["swiftCode"])
+	assert.Nil(t, response["next"])
+}
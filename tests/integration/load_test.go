@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+const duplicatesCSV = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+	"PL,LOADPLPWXXX,BIC11,Load Bank,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,LOADPLPW001,BIC11,Load Branch,Branch Street 2,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,LOADPLPWXXX,BIC11,Load Bank,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,LOADPLPW001,BIC11,Moved Branch,New Street 9,WARSZAWA,POLAND,Europe/Warsaw\n"
+
+func loadDuplicates(t *testing.T, policy services.DuplicatePolicy) *repositories.SwiftCodeRepository {
+	SetupTestDatabase(t)
+	CleanupTestDatabase(t)
+	t.Cleanup(func() { CleanupTestDatabase(t) })
+
+	report, err := services.LoadSwiftCodesFromReader(db.DB, strings.NewReader(duplicatesCSV), services.DefaultColumnMapping, policy, services.StreamOptions{})
+	assert.NoError(t, err, "Repeated codes must not break the load")
+	assert.Len(t, report.Duplicates, 2)
+	return repositories.NewSwiftCodeRepository(db.DB)
+}
+
+func TestLoadSwiftCodesFromReader_LastWins(t *testing.T) {
+	repo := loadDuplicates(t, services.DuplicateLastWins)
+
+	branch, err := repo.GetBySwiftCode("LOADPLPW001")
+	assert.NoError(t, err)
+	assert.Equal(t, "Moved Branch", branch.BankName)
+	assert.Equal(t, "New Street 9", branch.Address)
+
+	hq, err := repo.GetBySwiftCode("LOADPLPWXXX")
+	assert.NoError(t, err)
+	assert.Equal(t, hq.ID, *branch.HeadquarterID)
+}
+
+func TestLoadSwiftCodesFromReader_Reject(t *testing.T) {
+	repo := loadDuplicates(t, services.DuplicateReject)
+
+	branch, err := repo.GetBySwiftCode("LOADPLPW001")
+	assert.NoError(t, err)
+	assert.Nil(t, branch, "A conflicting code must not be loaded")
+
+	hq, err := repo.GetBySwiftCode("LOADPLPWXXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, hq, "Identical duplicates are loaded once")
+}
+
+// TestLoadSwiftCodesFromReader_CopyFails - a failing COPY is reported with its own error, not the cancelled parse
+func TestLoadSwiftCodesFromReader_CopyFails(t *testing.T) {
+	SetupTestDatabase(t)
+	CleanupTestDatabase(t)
+	t.Cleanup(func() { CleanupTestDatabase(t) })
+
+	_, err := services.LoadSwiftCodesFromReader(db.DB, strings.NewReader(duplicatesCSV), services.DefaultColumnMapping, services.DuplicateFirstWins, services.StreamOptions{})
+	assert.NoError(t, err)
+
+	// The first row is already stored; the rest keeps the parser busy when COPY fails.
+	var input strings.Builder
+	input.WriteString(duplicatesCSV[:strings.Index(duplicatesCSV, "\n")+1])
+	input.WriteString("PL,LOADPLPWXXX,BIC11,Load Bank,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n")
+	for i := 1; i < 1000; i++ {
+		fmt.Fprintf(&input, "PL,COPYPLPW%03d,BIC11,Copy Bank %d,Street %d,WARSZAWA,POLAND,Europe/Warsaw\n", i, i, i)
+	}
+
+	_, err = services.LoadSwiftCodesFromReader(db.DB, strings.NewReader(input.String()), services.DefaultColumnMapping, services.DuplicateFirstWins,
+		services.StreamOptions{Buffer: 16, BatchSize: 16})
+	var pgErr *pgconn.PgError
+	assert.True(t, errors.As(err, &pgErr), "Expected the unique violation, got: %v", err)
+	assert.False(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupSwiftCodes(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.POST("/v1/swift-codes/lookup", handler.LookupSwiftCodes)
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"swiftCodes": []string{"BANKDE44XXX", "bankus33", "BANKUS33ZZZ", "INVALID"},
+	})
+	req, _ := http.NewRequest("POST", "/v1/swift-codes/lookup", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	statuses := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		statuses = append(statuses, result["status"].(string))
+	}
+	assert.Equal(t, []string{"FOUND", "FOUND", "NOT_FOUND", "INVALID"}, statuses)
+	assert.Equal(t, "GERMANY", response.Results[0]["data"].(map[string]interface{})["countryName"])
+	assert.Equal(t, "BANKUS33XXX", response.Results[1]["data"].(map[string]interface{})["swiftCode"])
+}
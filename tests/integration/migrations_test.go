@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/migrations"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations_DownAndUp(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	ctx := context.Background()
+	migrator, err := migrations.NewMigrator(db.DB)
+	assert.NoError(t, err)
+
+	// Everything is applied by SetupTestDatabase, so a second run is a no-op.
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	reverted, err := migrator.Down(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, reverted)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt, "The latest migration should be pending after down")
+
+	applied, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, reverted.Version, applied[0].Version)
+}
+
+func TestMigrations_StoreBIC11(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	ctx := context.Background()
+	migrator, err := migrations.NewMigrator(db.DB)
+	assert.NoError(t, err)
+
+	// Revert down to the schema before 0007_store_bic11.
+	for {
+		reverted, err := migrator.Down(ctx)
+		assert.NoError(t, err)
+		if reverted == nil || reverted.Version <= 7 {
+			break
+		}
+	}
+
+	// Before 0007 an 8-character code was stored as is, as a branch.
+	_, err = db.DB.Exec(`INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type)
+		VALUES ('OLDBPLPW', 'Old Bank', 'UNKNOWN', 'PL', 'POLAND', false, 'BIC8'),
+			('OLDBPLPW001', 'Old Bank Branch', 'UNKNOWN', 'PL', 'POLAND', false, 'BIC11');`)
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	headquarter, err := repo.GetBySwiftCode("OLDBPLPWXXX")
+	assert.NoError(t, err)
+	if assert.NotNil(t, headquarter) {
+		assert.True(t, headquarter.IsHeadquarter)
+		assert.Nil(t, headquarter.HeadquarterID)
+
+		branch, err := repo.GetBySwiftCode("OLDBPLPW001")
+		assert.NoError(t, err)
+		if assert.NotNil(t, branch) && assert.NotNil(t, branch.HeadquarterID) {
+			assert.Equal(t, headquarter.ID, *branch.HeadquarterID)
+		}
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mroczekDNF/swift-api/internal/db"
 	"github.com/mroczekDNF/swift-api/internal/handlers"
 	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/routes"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,7 +60,7 @@ func TestAddSwiftCode_Success_HQ(t *testing.T) {
 	assert.Equal(t, "Credit Agricole HQ", swiftCode.BankName)
 	assert.Equal(t, "456 Paris Ave", swiftCode.Address)
 	assert.Equal(t, "FR", swiftCode.CountryISO2)
-	assert.Equal(t, "France", swiftCode.CountryName)
+	assert.Equal(t, "FRANCE", swiftCode.CountryName)
 	assert.True(t, swiftCode.IsHeadquarter)
 }
 
@@ -104,7 +107,7 @@ func TestAddSwiftCode_Success_Branch_WithHQ(t *testing.T) {
 	assert.Equal(t, "Canadian Bank Branch A", swiftCode.BankName)
 	assert.Equal(t, "456 Nice Blvd", swiftCode.Address)
 	assert.Equal(t, "CA", swiftCode.CountryISO2)
-	assert.Equal(t, "Canada", swiftCode.CountryName)
+	assert.Equal(t, "CANADA", swiftCode.CountryName)
 	assert.False(t, swiftCode.IsHeadquarter)
 
 	assert.NotNil(t, swiftCode.HeadquarterID)
@@ -149,5 +152,99 @@ func TestAddSwiftCode_Conflict(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Equal(t, "SWIFT code already exists in the database", response["error"])
+	assert.Equal(t, "DUPLICATE_SWIFT_CODE", response["code"])
+	assert.Equal(t, "SWIFT code already exists in the database", response["detail"])
+}
+
+func TestAddSwiftCode_ConcurrentRequests(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	jsonBody, _ := json.Marshal(gin.H{
+		"swiftCode":     "RACEFRPPXXX",
+		"bankName":      "Race Bank",
+		"countryISO2":   "FR",
+		"countryName":   "France",
+		"isHeadquarter": true,
+	})
+
+	// All requests pass the existence check together, so all but one must lose on the unique constraint.
+	const requests = 20
+	statuses := make(chan int, requests)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", "/v1/swift-codes", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			<-start
+			router.ServeHTTP(recorder, req)
+			statuses <- recorder.Code
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(statuses)
+
+	counts := make(map[int]int)
+	for status := range statuses {
+		counts[status]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusConflict: requests - 1}, counts)
+
+	swiftCode, err := repo.GetBySwiftCode("RACEFRPPXXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, swiftCode)
+}
+
+// TestAddSwiftCode_BIC8 - a code posted in its 8-character form is stored as BIC11 and addressed by either form
+func TestAddSwiftCode_BIC8(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := routes.SetupRouter(db.DB, time.Hour)
+
+	created := sendWithHeader(router, "POST", "/v1/swift-codes", "", "", gin.H{
+		"swiftCode":     "eghtfrpp",
+		"bankName":      "Eight Bank",
+		"countryISO2":   "FR",
+		"countryName":   "France",
+		"isHeadquarter": true,
+	})
+	assert.Equal(t, http.StatusOK, created.Code)
+
+	duplicate := sendWithHeader(router, "POST", "/v1/swift-codes", "", "", gin.H{
+		"swiftCode":     "EGHTFRPPXXX",
+		"bankName":      "Eight Bank",
+		"countryISO2":   "FR",
+		"countryName":   "France",
+		"isHeadquarter": true,
+	})
+	assert.Equal(t, http.StatusConflict, duplicate.Code, "A BIC8 and its BIC11 are the same record")
+
+	for _, path := range []string{"/v1/swift-codes/EGHTFRPP", "/v1/swift-codes/eghtfrppxxx"} {
+		read := sendWithHeader(router, "GET", path, "", "", nil)
+		assert.Equal(t, http.StatusOK, read.Code, path)
+		assert.Contains(t, read.Body.String(), `"swiftCode":"EGHTFRPPXXX"`)
+	}
+
+	patched := sendWithHeader(router, "PATCH", "/v1/swift-codes/EGHTFRPP", "If-Match", "*", gin.H{"address": "1 Rue de Huit"})
+	assert.Equal(t, http.StatusOK, patched.Code)
+
+	deleted := sendWithHeader(router, "DELETE", "/v1/swift-codes/EGHTFRPP", "If-Match", "*", nil)
+	assert.Equal(t, http.StatusOK, deleted.Code)
+
+	gone := sendWithHeader(router, "GET", "/v1/swift-codes/EGHTFRPPXXX", "", "", nil)
+	assert.Equal(t, http.StatusNotFound, gone.Code)
 }
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func postBatch(t *testing.T, repo *repositories.SwiftCodeRepository, body gin.H) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.POST("/v1/swift-codes/batch", handler.AddSwiftCodesBatch)
+
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/v1/swift-codes/batch", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func newBankItem(swiftCode string, isHeadquarter bool) gin.H {
+	return gin.H{
+		"swiftCode":     swiftCode,
+		"bankName":      "Onboarded Bank",
+		"countryISO2":   "PL",
+		"countryName":   "Poland",
+		"isHeadquarter": isHeadquarter,
+	}
+}
+
+func TestAddSwiftCodesBatch_LinksBranchesInAnyOrder(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	recorder := postBatch(t, repo, gin.H{
+		"swiftCodes": []gin.H{
+			newBankItem("ONBDPLPWAAA", false),
+			newBankItem("ONBDPLPWXXX", true),
+			newBankItem("ONBDPLPWBBB", false),
+			newBankItem("BANKUS33XXX", true),
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), response["created"])
+	assert.Equal(t, float64(1), response["failed"])
+
+	headquarter, err := repo.GetBySwiftCode("ONBDPLPWXXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, headquarter)
+
+	for _, code := range []string{"ONBDPLPWAAA", "ONBDPLPWBBB"} {
+		branch, err := repo.GetBySwiftCode(code)
+		assert.NoError(t, err)
+		if assert.NotNil(t, branch) && assert.NotNil(t, branch.HeadquarterID) {
+			assert.Equal(t, headquarter.ID, *branch.HeadquarterID)
+		}
+	}
+}
+
+func TestAddSwiftCodesBatch_AllOrNothingRollsBack(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	recorder := postBatch(t, repo, gin.H{
+		"swiftCodes": []gin.H{
+			newBankItem("ONBDPLPWXXX", true),
+			newBankItem("BANKUS33XXX", true),
+		},
+		"allOrNothing": true,
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	swiftCode, err := repo.GetBySwiftCode("ONBDPLPWXXX")
+	assert.NoError(t, err)
+	assert.Nil(t, swiftCode, "The batch transaction should be rolled back")
+}
+
+func TestAddSwiftCodesBatch_DuplicateMidBatch(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	recorder := postBatch(t, repo, gin.H{"swiftCodes": []gin.H{newBankItem("ONBDPLPWXXX", true)}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = postBatch(t, repo, gin.H{
+		"swiftCodes": []gin.H{
+			newBankItem("ONBDPLPWAAA", false),
+			newBankItem("ONBDPLPWXXX", true),
+			newBankItem("ONBDPLPWBBB", false),
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "A duplicate must not abort the batch transaction")
+
+	var response struct {
+		Created int                        `json:"created"`
+		Results []handlers.BatchItemResult `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, handlers.BatchStatusDuplicate, response.Results[1].Status)
+
+	branch, err := repo.GetBySwiftCode("ONBDPLPWBBB")
+	assert.NoError(t, err)
+	assert.NotNil(t, branch, "Items after the duplicate are stored")
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func searchSwiftCodes(t *testing.T, query string) []interface{} {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.GET("/v1/swift-codes/search", handler.SearchSwiftCodes)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/search?q="+query, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	return response["results"].([]interface{})
+}
+
+func TestSearchSwiftCodes_PartialBankName(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	results := searchSwiftCodes(t, "agricol")
+	assert.NotEmpty(t, results)
+	assert.Equal(t, "BANKFR55XXX", results[0].(map[string]interface{})["swiftCode"])
+}
+
+func TestSearchSwiftCodes_RankedByRelevance(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	results := searchSwiftCodes(t, "state%20bank%20branch")
+	assert.GreaterOrEqual(t, len(results), 2)
+
+	first := results[0].(map[string]interface{})
+	assert.Contains(t, []string{"BANKIN88AAA", "BANKIN99BBB"}, first["swiftCode"])
+	for i := 1; i < len(results); i++ {
+		previous := results[i-1].(map[string]interface{})["score"].(float64)
+		current := results[i].(map[string]interface{})["score"].(float64)
+		assert.GreaterOrEqual(t, previous, current)
+	}
+}
+
+func TestSearchSwiftCodes_Address(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	results := searchSwiftCodes(t, "maple")
+	codes := make([]string, 0, len(results))
+	for _, result := range results {
+		codes = append(codes, result.(map[string]interface{})["swiftCode"].(string))
+	}
+	assert.ElementsMatch(t, []string{"BANKCA66XXX", "BANKCA77AAA"}, codes)
+}
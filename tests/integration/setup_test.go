@@ -31,7 +31,7 @@ func SetupTestDatabase(t *testing.T) {
 }
 
 func CleanupTestDatabase(t *testing.T) {
-	_, err := db.DB.Exec("TRUNCATE TABLE swift_codes RESTART IDENTITY CASCADE;")
+	_, err := db.DB.Exec("TRUNCATE TABLE swift_codes, idempotency_keys RESTART IDENTITY CASCADE;")
 	assert.NoError(t, err, "Error cleaning up the test database")
 	log.Println("Test database cleaned up")
 }
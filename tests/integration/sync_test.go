@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncSwiftCodesToDatabase(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	records, err := services.ParseSwiftCodes("../../data/test_data.csv")
+	assert.NoError(t, err)
+
+	// Drop the US headquarter, rename a branch and add a new headquarter for a previously independent branch.
+	var parsed []models.SwiftCode
+	for _, record := range records {
+		switch record.SwiftCode {
+		case "BANKUS33XXX":
+			continue
+		case "BANKUS33ABC":
+			record.BankName = "Test Bank Branch A (renamed)"
+		}
+		parsed = append(parsed, record)
+	}
+	parsed = append(parsed, models.SwiftCode{
+		SwiftCode:     "BANKGB22XXX",
+		BankName:      "UK Bank HQ",
+		Address:       "1 London Rd",
+		CountryISO2:   "GB",
+		CountryName:   "United Kingdom",
+		IsHeadquarter: true,
+	})
+
+	result, err := services.SyncSwiftCodesToDatabase(db.DB, parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, 1, result.Changed)
+	assert.Equal(t, 1, result.Removed)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+
+	removed, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.Nil(t, removed)
+
+	renamed, err := repo.GetBySwiftCode("BANKUS33ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Bank Branch A (renamed)", renamed.BankName)
+	assert.Nil(t, renamed.HeadquarterID, "Branch of a removed headquarter should be unlinked")
+
+	newHQ, err := repo.GetBySwiftCode("BANKGB22XXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, newHQ)
+
+	ukBranch, err := repo.GetBySwiftCode("BANKGB22AAA")
+	assert.NoError(t, err)
+	assert.NotNil(t, ukBranch.HeadquarterID, "Branch should be linked to its new headquarter")
+	assert.Equal(t, newHQ.ID, *ukBranch.HeadquarterID)
+
+	// A second run with the same file changes nothing.
+	result, err = services.SyncSwiftCodesToDatabase(db.DB, parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, services.SyncResult{Unchanged: len(parsed)}, result)
+}
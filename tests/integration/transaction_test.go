@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTx_RollbackKeepsHierarchy(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+
+	hq, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, hq)
+
+	// Simulate a crash after detaching the branches but before deleting the headquarter.
+	err = repo.WithTx(context.Background(), func(tx repositories.SwiftCodeRepositoryInterface) error {
+		if err := tx.DetachBranchesFromHeadquarter(hq.ID); err != nil {
+			return err
+		}
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+
+	branch, err := repo.GetBySwiftCode("BANKUS33ABC")
+	assert.NoError(t, err)
+	assert.NotNil(t, branch.HeadquarterID, "Detaching should have been rolled back")
+	assert.Equal(t, hq.ID, *branch.HeadquarterID)
+
+	stillThere, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, stillThere)
+}
+
+func TestWithTx_CommitDeletesHeadquarterAndDetachesBranches(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+
+	hq, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+
+	err = repo.WithTx(context.Background(), func(tx repositories.SwiftCodeRepositoryInterface) error {
+		if err := tx.DetachBranchesFromHeadquarter(hq.ID); err != nil {
+			return err
+		}
+		return tx.DeleteSwiftCode("BANKUS33XXX", hq.Version)
+	})
+	assert.NoError(t, err)
+
+	branch, err := repo.GetBySwiftCode("BANKUS33ABC")
+	assert.NoError(t, err)
+	assert.Nil(t, branch.HeadquarterID)
+
+	deleted, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/db"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSwiftCode_FixAddress(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.PUT("/v1/swift-codes/:swiftCode", handler.UpdateSwiftCode)
+
+	body := gin.H{
+		"swiftCode":     "BANKUS33ABC",
+		"bankName":      "Test Bank Branch A",
+		"address":       "457 Test Ave",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": false,
+	}
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("PUT", "/v1/swift-codes/BANKUS33ABC", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, req)
+
+	log.Printf("JSON Response: %s", recorder.Body.String())
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	branch, err := repo.GetBySwiftCode("BANKUS33ABC")
+	assert.NoError(t, err)
+	assert.Equal(t, "457 Test Ave", branch.Address)
+
+	hq, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.NotNil(t, branch.HeadquarterID, "Branch should stay linked to its headquarter")
+	assert.Equal(t, hq.ID, *branch.HeadquarterID)
+}
+
+func TestPatchSwiftCode_HeadquarterMustMatchCode(t *testing.T) {
+	SetupTestDatabase(t)
+	defer CleanupTestDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	repo := repositories.NewSwiftCodeRepository(db.DB)
+	handler := handlers.NewSwiftCodeHandler(repo)
+	router.PATCH("/v1/swift-codes/:swiftCode", handler.PatchSwiftCode)
+
+	jsonBody, _ := json.Marshal(gin.H{"isHeadquarter": false})
+	req, _ := http.NewRequest("PATCH", "/v1/swift-codes/BANKUS33XXX", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", "*")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	log.Printf("JSON Response: %s", recorder.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "A code ending in XXX cannot be demoted to a branch")
+
+	hq, err := repo.GetBySwiftCode("BANKUS33XXX")
+	assert.NoError(t, err)
+	assert.True(t, hq.IsHeadquarter)
+
+	branch, err := repo.GetBySwiftCode("BANKUS33ABC")
+	assert.NoError(t, err)
+	assert.NotNil(t, branch.HeadquarterID, "Branches should stay linked to the headquarter")
+	assert.Equal(t, hq.ID, *branch.HeadquarterID)
+}
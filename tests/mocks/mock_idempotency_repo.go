@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyKeyRepository) ReserveKey(key, requestHash string, window time.Duration) (*models.IdempotencyKey, error) {
+	args := m.Called(key, requestHash, window)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.IdempotencyKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIdempotencyKeyRepository) SaveResponse(key string, statusCode int, contentType, etag string, body []byte) error {
+	args := m.Called(key, statusCode, contentType, etag, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) ReleaseKey(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) DeleteExpiredKeys(window time.Duration) (int64, error) {
+	args := m.Called(window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var _ repositories.IdempotencyKeyRepositoryInterface = (*MockIdempotencyKeyRepository)(nil)
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/mroczekDNF/swift-api/internal/models"
 	"github.com/mroczekDNF/swift-api/internal/repositories"
 	"github.com/stretchr/testify/mock"
@@ -18,13 +20,21 @@ func (m *MockSwiftCodeRepository) GetBySwiftCode(code string) (*models.SwiftCode
 	return nil, args.Error(1)
 }
 
+func (m *MockSwiftCodeRepository) GetBySwiftCodes(codes []string) ([]models.SwiftCode, error) {
+	args := m.Called(codes)
+	if args.Get(0) != nil {
+		return args.Get(0).([]models.SwiftCode), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockSwiftCodeRepository) GetBranchesByHeadquarter(code string) ([]models.SwiftCode, error) {
 	args := m.Called(code)
 	return args.Get(0).([]models.SwiftCode), args.Error(1)
 }
 
-func (m *MockSwiftCodeRepository) DeleteSwiftCode(code string) error {
-	args := m.Called(code)
+func (m *MockSwiftCodeRepository) DeleteSwiftCode(code string, version int64) error {
+	args := m.Called(code, version)
 	return args.Error(0)
 }
 
@@ -41,14 +51,48 @@ func (m *MockSwiftCodeRepository) GetByCountryISO2(countryISO2 string) ([]models
 	return nil, args.Error(1)
 }
 
+func (m *MockSwiftCodeRepository) ListSwiftCodes(filter repositories.SwiftCodeFilter) ([]models.SwiftCode, error) {
+	args := m.Called(filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]models.SwiftCode), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockSwiftCodeRepository) SearchSwiftCodes(query string, limit int) ([]repositories.SwiftCodeSearchResult, error) {
+	args := m.Called(query, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]repositories.SwiftCodeSearchResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockSwiftCodeRepository) InsertSwiftCode(swift *models.SwiftCode) error {
 	args := m.Called(swift)
 	return args.Error(0)
 }
 
+func (m *MockSwiftCodeRepository) UpdateSwiftCode(swift *models.SwiftCode) error {
+	args := m.Called(swift)
+	return args.Error(0)
+}
+
 func (m *MockSwiftCodeRepository) AssignBranchesToHeadquarter(headquarterCode string) error {
 	args := m.Called(headquarterCode)
 	return args.Error(0)
 }
 
+func (m *MockSwiftCodeRepository) FindHeadquarterLinkIssues() ([]models.HeadquarterLinkIssue, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).([]models.HeadquarterLinkIssue), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// WithTx runs fn directly against the mock; expectations set on the mock apply inside the transaction.
+func (m *MockSwiftCodeRepository) WithTx(ctx context.Context, fn func(repo repositories.SwiftCodeRepositoryInterface) error) error {
+	return fn(m)
+}
+
 var _ repositories.SwiftCodeRepositoryInterface = (*MockSwiftCodeRepository)(nil)
@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/pkg/bic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBICParse_BIC11(t *testing.T) {
+	code, err := bic.Parse(" bpkopLpw123 ")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "BPKOPLPW123", code.String())
+	assert.Equal(t, "BPKO", code.InstitutionCode())
+	assert.Equal(t, "PL", code.CountryCode())
+	assert.Equal(t, "PW", code.LocationCode())
+	assert.Equal(t, "123", code.BranchCode())
+	assert.Equal(t, "BPKOPLPW", code.BIC8())
+	assert.Equal(t, "BPKOPLPW123", code.BIC11())
+	assert.False(t, code.IsBIC8())
+	assert.False(t, code.IsPrimaryOffice())
+	assert.Equal(t, "BPKOPLPWXXX", code.PrimaryOffice().String())
+}
+
+func TestBICParse_BIC8IsPrimaryOffice(t *testing.T) {
+	code, err := bic.Parse("BPKOPLPW")
+	assert.NoError(t, err)
+
+	assert.True(t, code.IsBIC8())
+	assert.True(t, code.IsPrimaryOffice())
+	assert.Equal(t, "XXX", code.BranchCode())
+	assert.Equal(t, "BPKOPLPWXXX", code.BIC11())
+	assert.True(t, bic.MustParse("BPKOPLPWXXX").IsPrimaryOffice())
+}
+
+func TestBICParse_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{"", bic.ErrInvalidLength},
+		{"BPKOPLPW1", bic.ErrInvalidLength},
+		{"BPKOPLPW12345", bic.ErrInvalidLength},
+		{"12345678", bic.ErrInvalidInstitutionCode},
+		{"BPKO1LPW", bic.ErrInvalidCountryCode},
+		{"BPKOPL-W", bic.ErrInvalidLocationCode},
+		{"BPKOPLPW12_", bic.ErrInvalidBranchCode},
+	}
+
+	for _, tt := range tests {
+		_, err := bic.Parse(tt.input)
+		assert.ErrorIs(t, err, tt.err, "input %q", tt.input)
+
+		var parseErr *bic.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.Equal(t, tt.input, parseErr.Input)
+		}
+	}
+}
+
+func TestBICCodeType(t *testing.T) {
+	assert.Equal(t, bic.CodeTypeBIC8, bic.CodeType("BPKOPLPW"))
+	assert.Equal(t, bic.CodeTypeBIC11, bic.CodeType("BPKOPLPWXXX"))
+	assert.Equal(t, bic.CodeTypeBIC11, bic.CodeType(""), "Records without a code are treated as BIC11")
+}
+
+func TestBICCanonical(t *testing.T) {
+	assert.Equal(t, "BPKOPLPWXXX", bic.Canonical(" bpkoplpw "))
+	assert.Equal(t, "BPKOPLPW123", bic.Canonical("BPKOPLPW123"))
+	assert.Equal(t, "BPKO-PL", bic.Canonical("bpko-pl"), "Invalid codes are only upper-cased")
+}
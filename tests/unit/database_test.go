@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateSwiftCodes(count int) []models.SwiftCode {
+	swiftCodes := make([]models.SwiftCode, count)
+	for i := range swiftCodes {
+		swiftCodes[i] = models.SwiftCode{
+			SwiftCode:   fmt.Sprintf("BANKPL%02d%03d", i/1000%100, i%1000),
+			BankName:    "Bank",
+			Address:     "Street",
+			CountryISO2: "PL",
+			CountryName: "POLAND",
+		}
+	}
+	return swiftCodes
+}
+
+// TestSaveSwiftCodesToDatabase_Batches - records are inserted in multi-row batches inside one transaction
+func TestSaveSwiftCodesToDatabase_Batches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	insert := regexp.QuoteMeta("INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone) VALUES")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1000))
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 500))
+	// Branches are linked to headquarters by prefix before the commit.
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = hq.id")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = NULL")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = services.SaveSwiftCodesToDatabase(db, generateSwiftCodes(1500))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSaveSwiftCodesToDatabase_RollbackOnFailure - a failing batch rolls back the whole load
+func TestSaveSwiftCodesToDatabase_RollbackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	insert := regexp.QuoteMeta("INSERT INTO swift_codes")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1000))
+	mock.ExpectExec(insert).WillReturnError(errors.New("duplicate key value"))
+	mock.ExpectRollback()
+
+	err = services.SaveSwiftCodesToDatabase(db, generateSwiftCodes(1500))
+	assert.EqualError(t, err, "duplicate key value")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
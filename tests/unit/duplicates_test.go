@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// duplicatesCSV repeats ABCDPLPWXXX identically and ABCDPLPW001 with another bank name and address.
+const duplicatesCSV = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+	"PL,ABCDPLPWXXX,BIC11,Bank HQ,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPW001,BIC11,Branch Bank,Branch Street 2,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPWXXX,BIC11,Bank HQ,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPW001,BIC11,Renamed Branch,Branch Street 2,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPW002,BIC11,Other Branch,Other Street 3,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPW001,BIC11,Moved Branch,New Street 9,WARSZAWA,POLAND,Europe/Warsaw\n"
+
+func bankNames(swiftCodes []models.SwiftCode) []string {
+	names := make([]string, 0, len(swiftCodes))
+	for _, code := range swiftCodes {
+		names = append(names, code.SwiftCode+" "+code.BankName)
+	}
+	return names
+}
+
+// TestParseSwiftCodesDuplicates tests that repeated codes are classified and listed.
+func TestParseSwiftCodesDuplicates(t *testing.T) {
+	_, report, err := services.ParseSwiftCodesFrom(strings.NewReader(duplicatesCSV), services.DefaultColumnMapping, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, report.Accepted)
+	assert.Equal(t, []services.DuplicateRecord{
+		{Line: 4, FirstLine: 2, SwiftCode: "ABCDPLPWXXX", Kind: services.DuplicateIdentical},
+		{Line: 5, FirstLine: 3, SwiftCode: "ABCDPLPW001", Kind: services.DuplicateConflicting},
+		{Line: 7, FirstLine: 3, SwiftCode: "ABCDPLPW001", Kind: services.DuplicateConflicting},
+	}, report.Duplicates)
+	assert.Equal(t, "6 records: 6 accepted, 0 rejected, 3 duplicates (2 conflicting)", report.Summary())
+}
+
+// TestParseSwiftCodesDuplicatePolicies tests the record kept under each policy.
+func TestParseSwiftCodesDuplicatePolicies(t *testing.T) {
+	tests := []struct {
+		policy    services.DuplicatePolicy
+		expected  []string
+		discarded []string
+	}{
+		{services.DuplicateFirstWins, []string{"ABCDPLPWXXX Bank HQ", "ABCDPLPW001 Branch Bank", "ABCDPLPW002 Other Branch"}, []string{}},
+		{services.DuplicateLastWins, []string{"ABCDPLPWXXX Bank HQ", "ABCDPLPW001 Moved Branch", "ABCDPLPW002 Other Branch"}, []string{}},
+		{services.DuplicateReject, []string{"ABCDPLPWXXX Bank HQ", "ABCDPLPW002 Other Branch"}, []string{"ABCDPLPW001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			swiftCodes, report, err := services.ParseSwiftCodesFrom(strings.NewReader(duplicatesCSV), services.DefaultColumnMapping, tt.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, bankNames(swiftCodes))
+			assert.Equal(t, tt.discarded, report.Discarded)
+		})
+	}
+}
+
+// TestStreamSwiftCodes_Duplicates tests that only first occurrences reach the sink and the resolution is reported.
+func TestStreamSwiftCodes_Duplicates(t *testing.T) {
+	var swiftCodes []models.SwiftCode
+	report, err := services.StreamSwiftCodes(context.Background(), strings.NewReader(duplicatesCSV), services.DefaultColumnMapping,
+		services.DuplicateLastWins, services.StreamOptions{}, func(batch []models.SwiftCode) error {
+			swiftCodes = append(swiftCodes, batch...)
+			return nil
+		})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ABCDPLPWXXX Bank HQ", "ABCDPLPW001 Branch Bank", "ABCDPLPW002 Other Branch"}, bankNames(swiftCodes))
+	assert.Equal(t, []string{"ABCDPLPW001 Moved Branch"}, bankNames(report.Replacements))
+	assert.Len(t, report.Duplicates, 3)
+}
+
+// TestLoadSwiftCodesFromReader_ResolvesDuplicates tests that the load applies the policy before committing.
+func TestLoadSwiftCodesFromReader_ResolvesDuplicates(t *testing.T) {
+	t.Run("last", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO swift_codes")).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes SET bank_name = $2, address = $3, country_iso2 = $4, country_name = $5, is_headquarter = $6, code_type = $7, town_name = $8, time_zone = $9 WHERE swift_code = $1;")).
+			WithArgs("ABCDPLPW001", "Moved Branch", "New Street 9", "PL", "POLAND", false, "BIC11", "WARSZAWA", "Europe/Warsaw").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = hq.id")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = NULL")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		_, err = services.LoadSwiftCodesFromReader(db, strings.NewReader(duplicatesCSV), services.DefaultColumnMapping, services.DuplicateLastWins, services.StreamOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(arrayArgConverter{}))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO swift_codes")).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM swift_codes WHERE swift_code = ANY($1);")).
+			WithArgs([]string{"ABCDPLPW001"}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = hq.id")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = NULL")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		report, err := services.LoadSwiftCodesFromReader(db, strings.NewReader(duplicatesCSV), services.DefaultColumnMapping, services.DuplicateReject, services.StreamOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ABCDPLPW001"}, report.Discarded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestParseDuplicatePolicy tests policy names.
+func TestParseDuplicatePolicy(t *testing.T) {
+	policy, err := services.ParseDuplicatePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, services.DuplicateFirstWins, policy)
+
+	policy, err = services.ParseDuplicatePolicy("Last")
+	assert.NoError(t, err)
+	assert.Equal(t, services.DuplicateLastWins, policy)
+
+	_, err = services.ParseDuplicatePolicy("merge")
+	assert.EqualError(t, err, `unsupported duplicate policy "merge" (expected one of: first, last, reject)`)
+}
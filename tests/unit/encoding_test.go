@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const encodingHeader = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n"
+
+// decodeAndParse parses input declared with enc, returning the detected encoding.
+func decodeAndParse(t *testing.T, input string, enc services.Encoding) ([]string, services.Encoding, services.ParseReport) {
+	reader, detected, err := services.DecodeInput(strings.NewReader(input), enc)
+	assert.NoError(t, err)
+
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(reader, services.DefaultColumnMapping, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, code := range swiftCodes {
+		names = append(names, code.BankName+"|"+code.Address+"|"+code.TownName)
+	}
+	return names, detected, report
+}
+
+// TestDecodeInput_UTF8BOM tests that a byte order mark is stripped and does not break the header.
+func TestDecodeInput_UTF8BOM(t *testing.T) {
+	input := "\xEF\xBB\xBF" + encodingHeader + "PL,ABCDPLPWXXX,BIC11,Bank Śląski,Plac Grzybowski 1,Łódź,POLAND,Europe/Warsaw\n"
+
+	names, detected, report := decodeAndParse(t, input, services.EncodingAuto)
+	assert.Equal(t, services.EncodingUTF8, detected)
+	assert.Empty(t, report.Rejected)
+	assert.Equal(t, []string{"Bank Śląski|Plac Grzybowski 1|Łódź"}, names)
+
+	_, _, err := services.DecodeInput(strings.NewReader(input), services.EncodingWindows1250)
+	assert.Error(t, err, "A byte order mark contradicts a declared 8-bit encoding")
+}
+
+// TestDecodeInput_Windows1250 tests that Polish names in Windows-1250 are detected and transcoded.
+func TestDecodeInput_Windows1250(t *testing.T) {
+	input, err := charmap.Windows1250.NewEncoder().String(encodingHeader +
+		"PL,ABCDPLPWXXX,BIC11,Bank Śląski,Żółkiewskiego 5,Łódź,POLAND,Europe/Warsaw\n")
+	assert.NoError(t, err)
+
+	names, detected, report := decodeAndParse(t, input, services.EncodingAuto)
+	assert.Equal(t, services.EncodingWindows1250, detected)
+	assert.Empty(t, report.Rejected)
+	assert.Equal(t, []string{"Bank Śląski|Żółkiewskiego 5|Łódź"}, names)
+}
+
+// TestDecodeInput_ISO88591 tests that German names in ISO-8859-1 are detected and transcoded.
+func TestDecodeInput_ISO88591(t *testing.T) {
+	input, err := charmap.ISO8859_1.NewEncoder().String(encodingHeader +
+		"DE,ABCDDEFFXXX,BIC11,Bankhaus Müller & Söhne,Große Straße 3,Köln,GERMANY,Europe/Berlin\n")
+	assert.NoError(t, err)
+
+	names, detected, report := decodeAndParse(t, input, services.EncodingAuto)
+	assert.Equal(t, services.EncodingISO88591, detected)
+	assert.Empty(t, report.Rejected)
+	assert.Equal(t, []string{"Bankhaus Müller & Söhne|Große Straße 3|Köln"}, names)
+}
+
+// TestDecodeInput_DeclaredEncoding tests that a declared encoding overrides detection.
+func TestDecodeInput_DeclaredEncoding(t *testing.T) {
+	// 0xB3 is "ł" in Windows-1250 and "³" in ISO-8859-1.
+	input := encodingHeader + "PL,ABCDPLPWXXX,BIC11,Bank Pocztowy,Sienkiewicza 1,Bia\xB3ystok,POLAND,Europe/Warsaw\n"
+
+	names, detected, _ := decodeAndParse(t, input, services.EncodingWindows1250)
+	assert.Equal(t, services.EncodingWindows1250, detected)
+	assert.Equal(t, []string{"Bank Pocztowy|Sienkiewicza 1|Białystok"}, names)
+
+	names, _, _ = decodeAndParse(t, input, services.EncodingISO88591)
+	assert.Equal(t, []string{"Bank Pocztowy|Sienkiewicza 1|Bia³ystok"}, names)
+}
+
+// TestParseSwiftCodesInvalidEncoding tests that rows with invalid byte sequences are rejected while the rest is kept.
+func TestParseSwiftCodesInvalidEncoding(t *testing.T) {
+	input := encodingHeader +
+		"PL,ABCDPLPWXXX,BIC11,Bank Śląski,Plac Grzybowski 1,Łódź,POLAND,Europe/Warsaw\n" +
+		"PL,ABCDPLPW001,BIC11,Bank \xB6l\xB1ski,Plac Grzybowski 1,Warszawa,POLAND,Europe/Warsaw\n" +
+		"PL,ABCDPLPW002,BIC11,Bank Zachodni,Rynek 9,Wrocław,POLAND,Europe/Warsaw\n"
+
+	names, detected, report := decodeAndParse(t, input, services.EncodingAuto)
+	assert.Equal(t, services.EncodingUTF8, detected, "A few broken rows should not make a UTF-8 file look like an 8-bit one")
+	assert.Len(t, names, 2)
+	assert.Len(t, report.Rejected, 1)
+	assert.Equal(t, 3, report.Rejected[0].Line)
+	assert.Equal(t, services.ReasonInvalidEncoding, report.Rejected[0].ReasonCode)
+	assert.Equal(t, "invalid byte sequence in bankName", report.Rejected[0].Reason)
+}
+
+// TestParseSwiftCodesNFC tests that names and addresses are normalised to NFC.
+func TestParseSwiftCodesNFC(t *testing.T) {
+	// "o" followed by a combining acute accent instead of the precomposed "ó".
+	input := encodingHeader + "PL,ABCDPLPWXXX,BIC11,Bank Spo\u0301łdzielczy,Ogrodowa 1,Gro\u0301jec,POLAND,Europe/Warsaw\n"
+
+	names, _, _ := decodeAndParse(t, input, services.EncodingUTF8)
+	assert.Equal(t, []string{"Bank Spółdzielczy|Ogrodowa 1|Grójec"}, names)
+}
+
+// TestParseEncoding tests encoding names and aliases.
+func TestParseEncoding(t *testing.T) {
+	for name, expected := range map[string]services.Encoding{
+		"":             services.EncodingAuto,
+		"UTF8":         services.EncodingUTF8,
+		"cp1250":       services.EncodingWindows1250,
+		"Windows-1250": services.EncodingWindows1250,
+		"latin1":       services.EncodingISO88591,
+	} {
+		enc, err := services.ParseEncoding(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, enc, name)
+	}
+
+	_, err := services.ParseEncoding("utf-16")
+	assert.EqualError(t, err, `unsupported encoding "utf-16" (expected one of: auto, utf-8, windows-1250, iso-8859-1)`)
+}
+
+// TestDecodeInput_Empty tests that an empty input decodes to an empty stream.
+func TestDecodeInput_Empty(t *testing.T) {
+	reader, detected, err := services.DecodeInput(strings.NewReader(""), services.EncodingAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, services.EncodingUTF8, detected)
+
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+// TestDecodeInput_Windows1250AfterASCII tests that 8-bit rows past an ASCII start of the file are detected.
+func TestDecodeInput_Windows1250AfterASCII(t *testing.T) {
+	ascii := encodingHeader + strings.Repeat("PL,ABCDPLPW001,BIC11,Bank Pocztowy,Sienkiewicza 1,Warszawa,POLAND,Europe/Warsaw\n", 1000)
+	tail, err := charmap.Windows1250.NewEncoder().String("PL,ABCDPLPWXXX,BIC11,Bank Śląski,Żółkiewskiego 5,Łódź,POLAND,Europe/Warsaw\n")
+	assert.NoError(t, err)
+
+	names, detected, report := decodeAndParse(t, ascii+tail, services.EncodingAuto)
+	assert.Equal(t, services.EncodingWindows1250, detected)
+	assert.Empty(t, report.Rejected)
+	assert.Equal(t, "Bank Śląski|Żółkiewskiego 5|Łódź", names[1])
+
+	_, detected, _ = decodeAndParse(t, ascii, services.EncodingAuto)
+	assert.Equal(t, services.EncodingUTF8, detected, "An input that is ASCII throughout is read as UTF-8")
+
+	_, _, err = services.DecodeInput(struct{ io.Reader }{strings.NewReader(ascii + tail)}, services.EncodingAuto)
+	assert.Error(t, err, "A reader that cannot be rewound needs a declared encoding")
+}
+
+// TestDecodeInput_Windows1250SharedLetters tests that letters also found in ISO-8859-1 count towards Windows-1250.
+func TestDecodeInput_Windows1250SharedLetters(t *testing.T) {
+	// 0xEA is "ę" in Windows-1250 and "ê" in ISO-8859-1; 0xF1 is "ń" or "ñ".
+	input, err := charmap.Windows1250.NewEncoder().String(encodingHeader +
+		"PL,ABCDPLPWXXX,BIC11,Bank Pekao,Aleja Pokoju 5,Częstochowa,POLAND,Europe/Warsaw\n" +
+		"PL,ABCDPLPW001,BIC11,Bank Pekao,Grunwaldzka 1,Gdańsk,POLAND,Europe/Warsaw\n")
+	assert.NoError(t, err)
+
+	names, detected, _ := decodeAndParse(t, input, services.EncodingAuto)
+	assert.Equal(t, services.EncodingWindows1250, detected)
+	assert.Equal(t, []string{"Bank Pekao|Aleja Pokoju 5|Częstochowa", "Bank Pekao|Grunwaldzka 1|Gdańsk"}, names)
+
+	// 0xE8 is "è" or "č", which are equally likely, so a French name stays ISO-8859-1.
+	input, err = charmap.ISO8859_1.NewEncoder().String(encodingHeader + "FR,ABCDFRPPXXX,BIC11,Banque Genève,Rue de la Paix 1,Paris,FRANCE,Europe/Paris\n")
+	assert.NoError(t, err)
+
+	names, detected, _ = decodeAndParse(t, input, services.EncodingAuto)
+	assert.Equal(t, services.EncodingISO88591, detected)
+	assert.Equal(t, []string{"Banque Genève|Rue de la Paix 1|Paris"}, names)
+}
@@ -34,9 +34,10 @@ func TestDeleteSwiftCode_Success(t *testing.T) {
 
 	// Mock behavior
 	mockRepo.On("GetBySwiftCode", swiftCode).Return(mockSwift, nil)
-	mockRepo.On("DeleteSwiftCode", swiftCode).Return(nil)
+	mockRepo.On("DeleteSwiftCode", swiftCode, int64(0)).Return(nil)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -64,6 +65,7 @@ func TestDeleteSwiftCode_NotFound(t *testing.T) {
 	mockRepo.On("GetBySwiftCode", swiftCode).Return(nil, nil)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -73,7 +75,8 @@ func TestDeleteSwiftCode_NotFound(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["message"], "SWIFT code not found")
+	assert.Equal(t, "SWIFT_NOT_FOUND", response["code"])
+	assert.Contains(t, response["detail"], "SWIFT code not found")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -91,6 +94,7 @@ func TestDeleteSwiftCode_ErrorRetrievingCode(t *testing.T) {
 	mockRepo.On("GetBySwiftCode", swiftCode).Return(nil, assert.AnError)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -100,7 +104,8 @@ func TestDeleteSwiftCode_ErrorRetrievingCode(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["message"], "Error retrieving SWIFT code")
+	assert.Equal(t, "INTERNAL_ERROR", response["code"])
+	assert.Contains(t, response["detail"], "Error retrieving SWIFT code")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -125,9 +130,10 @@ func TestDeleteSwiftCode_ErrorDeleting(t *testing.T) {
 
 	// Simulate a successful retrieval but failure on delete
 	mockRepo.On("GetBySwiftCode", swiftCode).Return(mockSwift, nil)
-	mockRepo.On("DeleteSwiftCode", swiftCode).Return(assert.AnError)
+	mockRepo.On("DeleteSwiftCode", swiftCode, int64(0)).Return(assert.AnError)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -137,7 +143,7 @@ func TestDeleteSwiftCode_ErrorDeleting(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["message"], "Error deleting SWIFT code")
+	assert.Contains(t, response["detail"], "Error deleting SWIFT code")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -163,9 +169,10 @@ func TestDeleteSwiftCode_HeadquarterWithBranches(t *testing.T) {
 	// Simulate successful retrieval and detachment of branches
 	mockRepo.On("GetBySwiftCode", swiftCode).Return(mockSwift, nil)
 	mockRepo.On("DetachBranchesFromHeadquarter", mockSwift.ID).Return(nil)
-	mockRepo.On("DeleteSwiftCode", swiftCode).Return(nil)
+	mockRepo.On("DeleteSwiftCode", swiftCode, int64(0)).Return(nil)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -203,6 +210,7 @@ func TestDeleteSwiftCode_ErrorDetachingBranches(t *testing.T) {
 	mockRepo.On("DetachBranchesFromHeadquarter", mockSwift.ID).Return(assert.AnError)
 
 	req, _ := http.NewRequest("DELETE", "/v1/swift-codes/"+swiftCode, nil)
+	req.Header.Set("If-Match", "*")
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -212,6 +220,6 @@ func TestDeleteSwiftCode_ErrorDetachingBranches(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["message"], "Error detaching branches")
+	assert.Contains(t, response["detail"], "Error detaching branches")
 	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,216 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func getWithIfNoneMatch(router *gin.Engine, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func versionedBranch(version int64) *models.SwiftCode {
+	headquarterID := int64(1)
+	return &models.SwiftCode{
+		ID:            2,
+		SwiftCode:     "BANKUS33ABC",
+		BankName:      "Test Bank Branch",
+		Address:       "456 Test Ave",
+		CountryISO2:   "US",
+		CountryName:   "UNITED STATES",
+		HeadquarterID: &headquarterID,
+		CodeType:      "BIC11",
+		TownName:      "NEW YORK",
+		TimeZone:      "America/New_York",
+		Version:       version,
+	}
+}
+
+// TestGetSwiftCodeDetails_ETag - the ETag is the record version and If-None-Match revalidates it with 304
+func TestGetSwiftCodeDetails_ETag(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil)
+
+	recorder := getWithIfNoneMatch(router, "/v1/swift-codes/BANKUS33ABC", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+
+	for _, ifNoneMatch := range []string{`"3"`, `W/"3"`, `"1", "3"`, "*"} {
+		recorder = getWithIfNoneMatch(router, "/v1/swift-codes/BANKUS33ABC", ifNoneMatch)
+		assert.Equal(t, http.StatusNotModified, recorder.Code, ifNoneMatch)
+		assert.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+		assert.Empty(t, recorder.Body.String())
+	}
+
+	recorder = getWithIfNoneMatch(router, "/v1/swift-codes/BANKUS33ABC", `"2"`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEmpty(t, recorder.Body.String())
+}
+
+// TestGetSwiftCodeDetails_HeadquarterETagFollowsBranches - a headquarter's ETag changes with its branches
+func TestGetSwiftCodeDetails_HeadquarterETagFollowsBranches(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	headquarter := &models.SwiftCode{ID: 1, SwiftCode: "BANKUS33XXX", BankName: "Test Bank", CountryISO2: "US", IsHeadquarter: true, Version: 5}
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(headquarter, nil)
+	mockRepo.On("GetBranchesByHeadquarter", "BANKUS33XXX").Return([]models.SwiftCode{*versionedBranch(1)}, nil).Once()
+	mockRepo.On("GetBranchesByHeadquarter", "BANKUS33XXX").Return([]models.SwiftCode{*versionedBranch(2)}, nil).Once()
+
+	first := getWithIfNoneMatch(router, "/v1/swift-codes/BANKUS33XXX", "")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Regexp(t, `^"5-[0-9a-f]{16}"$`, first.Header().Get("ETag"))
+
+	second := getWithIfNoneMatch(router, "/v1/swift-codes/BANKUS33XXX", first.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, second.Code, "An updated branch should invalidate the cached headquarter")
+	assert.NotEqual(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+}
+
+// TestUpdateSwiftCode_RequiresIfMatch - an update without If-Match is refused with 428
+func TestUpdateSwiftCode_RequiresIfMatch(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil)
+
+	recorder := sendJSON(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", map[string]interface{}{"address": "789 New Ave"})
+
+	assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+	assert.Equal(t, handlers.CodePreconditionRequired, decodeProblem(t, recorder).Code)
+	mockRepo.AssertNotCalled(t, "UpdateSwiftCode", mock.Anything)
+}
+
+// TestUpdateSwiftCode_StaleIfMatch - an update based on an older version is refused with 412
+func TestUpdateSwiftCode_StaleIfMatch(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil)
+
+	for _, ifMatch := range []string{`"2"`, `W/"3"`} {
+		recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", ifMatch, map[string]interface{}{"address": "789 New Ave"})
+
+		assert.Equal(t, http.StatusPreconditionFailed, recorder.Code, ifMatch)
+		assert.Equal(t, handlers.CodePreconditionFailed, decodeProblem(t, recorder).Code)
+	}
+	mockRepo.AssertNotCalled(t, "UpdateSwiftCode", mock.Anything)
+}
+
+// TestUpdateSwiftCode_MatchingIfMatch - the update is guarded by the version it was checked against
+func TestUpdateSwiftCode_MatchingIfMatch(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	expected := versionedBranch(3)
+	expected.Address = "789 New Ave"
+	updated := versionedBranch(4)
+	updated.Address = "789 New Ave"
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil).Once()
+	mockRepo.On("UpdateSwiftCode", expected).Return(nil)
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(updated, nil).Once()
+
+	recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", `"3"`, map[string]interface{}{"address": "789 New Ave"})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"4"`, recorder.Header().Get("ETag"), "The response carries the ETag of the updated record")
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUpdateSwiftCode_ConcurrentChange - a change committed after the check is reported with 412
+func TestUpdateSwiftCode_ConcurrentChange(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil)
+	mockRepo.On("UpdateSwiftCode", mock.Anything).Return(fmt.Errorf("%w: BANKUS33ABC", repositories.ErrVersionConflict))
+
+	recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", `"3"`, map[string]interface{}{"address": "789 New Ave"})
+
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.Equal(t, handlers.CodePreconditionFailed, decodeProblem(t, recorder).Code)
+}
+
+// TestDeleteSwiftCode_IfMatch - a delete needs the current ETag and is guarded by its version
+func TestDeleteSwiftCode_IfMatch(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil)
+	mockRepo.On("DeleteSwiftCode", "BANKUS33ABC", int64(3)).Return(nil).Once()
+
+	recorder := sendIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33ABC", "", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+
+	recorder = sendIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33ABC", `"2"`, nil)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+	recorder = sendIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33ABC", `"3"`, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteSwiftCode_HeadquarterIfMatch - a headquarter's If-Match is checked against the ETag covering its branches
+func TestDeleteSwiftCode_HeadquarterIfMatch(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	headquarter := &models.SwiftCode{ID: 1, SwiftCode: "BANKUS33XXX", BankName: "Test Bank", CountryISO2: "US", IsHeadquarter: true, Version: 5}
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(headquarter, nil)
+	mockRepo.On("GetBranchesByHeadquarter", "BANKUS33XXX").Return([]models.SwiftCode{*versionedBranch(1)}, nil)
+	mockRepo.On("DetachBranchesFromHeadquarter", int64(1)).Return(nil)
+	mockRepo.On("DeleteSwiftCode", "BANKUS33XXX", int64(5)).Return(nil)
+
+	etag := getWithIfNoneMatch(router, "/v1/swift-codes/BANKUS33XXX", "").Header().Get("ETag")
+
+	recorder := sendIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33XXX", `"5"`, nil)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code, "The bare version is not the headquarter's ETag")
+
+	recorder = sendIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33XXX", etag, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestMutations_BIC8Path - PUT, PATCH and DELETE address a stored <BIC8>XXX record by its BIC8, like GET
+func TestMutations_BIC8Path(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	headquarter := &models.SwiftCode{ID: 1, SwiftCode: "BANKUS33XXX", BankName: "Test Bank", CountryISO2: "US", CountryName: "UNITED STATES",
+		IsHeadquarter: true, CodeType: "BIC11", Address: "UNKNOWN", Version: 5}
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(headquarter, nil)
+	mockRepo.On("UpdateSwiftCode", mock.Anything).Return(nil).Twice()
+	mockRepo.On("GetBranchesByHeadquarter", "BANKUS33XXX").Return([]models.SwiftCode{}, nil)
+	mockRepo.On("DetachBranchesFromHeadquarter", int64(1)).Return(nil)
+	mockRepo.On("DeleteSwiftCode", "BANKUS33XXX", int64(5)).Return(nil)
+
+	recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/bankus33", "*", map[string]interface{}{"address": "789 New Ave"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = sendIfMatch(router, "PUT", "/v1/swift-codes/BANKUS33", "*", map[string]interface{}{
+		"swiftCode": "BANKUS33", "bankName": "Test Bank", "countryISO2": "US", "countryName": "United States", "isHeadquarter": true,
+	})
+	assert.Equal(t, http.StatusOK, recorder.Code, "The BIC8 in the body names the same record")
+
+	recorder = sendIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33", "*", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
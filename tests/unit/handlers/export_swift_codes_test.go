@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func exportTestCodes(from, to int) []models.SwiftCode {
+	codes := make([]models.SwiftCode, 0, to-from)
+	for i := from; i < to; i++ {
+		codes = append(codes, models.SwiftCode{
+			SwiftCode:   fmt.Sprintf("BANKPL%02d%03d", i/1000, i%1000),
+			BankName:    "Export Bank",
+			Address:     "UL. TESTOWA 1, WARSZAWA",
+			CountryISO2: "PL",
+			CountryName: "Poland",
+			TownName:    "WARSZAWA",
+			TimeZone:    "Europe/Warsaw",
+		})
+	}
+	return codes
+}
+
+func TestExportSwiftCodes_CSVCanBeReimported(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	firstPage := exportTestCodes(0, 1000)
+	mockRepo.On("ListSwiftCodes", repositories.SwiftCodeFilter{SortBy: "swiftCode", Limit: 1000}).Return(firstPage, nil)
+	mockRepo.On("ListSwiftCodes", mock.MatchedBy(func(filter repositories.SwiftCodeFilter) bool {
+		return filter.After != nil && filter.After.SwiftCode == firstPage[999].SwiftCode
+	})).Return(exportTestCodes(1000, 1001), nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/export", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(recorder.Body.String(), "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n"))
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	assert.NoError(t, os.WriteFile(path, recorder.Body.Bytes(), 0o600))
+
+	imported, err := services.ParseSwiftCodes(path)
+	assert.NoError(t, err)
+	assert.Len(t, imported, 1001)
+	assert.Equal(t, "BANKPL01000", imported[1000].SwiftCode)
+	assert.Equal(t, "BIC11", imported[0].CodeType)
+	assert.Equal(t, "POLAND", imported[0].CountryName)
+	assert.Equal(t, "Europe/Warsaw", imported[0].TimeZone)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportSwiftCodes_NDJSONByCountry(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("ListSwiftCodes", repositories.SwiftCodeFilter{CountryISO2: "PL", SortBy: "swiftCode", Limit: 1000}).
+		Return(exportTestCodes(0, 3), nil).Once()
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/export?format=ndjson&country=pl", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(recorder.Body.String()))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "BANKPL00000", lines[0]["swiftCode"])
+	assert.Equal(t, "POLAND", lines[0]["countryName"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportSwiftCodes_InvalidParameters(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	for _, query := range []string{"format=xml", "country=XQ"} {
+		req, _ := http.NewRequest("GET", "/v1/swift-codes/export?"+query, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		assert.Equal(t, handlers.CodeInvalidQuery, decodeProblem(t, recorder).Code)
+	}
+	mockRepo.AssertNotCalled(t, "ListSwiftCodes", mock.Anything)
+}
+
+func TestExportSwiftCodes_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("ListSwiftCodes", mock.Anything).Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/export", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, handlers.CodeInternalError, decodeProblem(t, recorder).Code)
+}
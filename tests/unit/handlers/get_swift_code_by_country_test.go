@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -58,7 +59,7 @@ func TestGetSwiftCodesByCountry_Success(t *testing.T) {
 
 	// Verify response structure
 	assert.Equal(t, countryISO2, response["countryISO2"])
-	assert.Equal(t, "United States", response["countryName"])
+	assert.Equal(t, "UNITED STATES", response["countryName"])
 
 	swiftCodes, ok := response["swiftCodes"].([]interface{})
 	assert.True(t, ok, "swiftCodes field should exist and be a list")
@@ -102,7 +103,8 @@ func TestGetSwiftCodesByCountry_NoRecords(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["error"], "No SWIFT codes found for the given country")
+	assert.Equal(t, "COUNTRY_NOT_FOUND", response["code"])
+	assert.Contains(t, response["detail"], "No SWIFT codes found for the given country")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -116,8 +118,8 @@ func TestGetSwiftCodesByCountry_NoSwiftCodesFound(t *testing.T) {
 
 	countryISO2 := "XX" // Country ISO2 code that does not exist in the mock database
 
-	// Mock behavior: return an empty list when queried with an invalid or missing country
-	mockRepo.On("GetByCountryISO2", countryISO2).Return([]models.SwiftCode{}, nil)
+	// Mock behavior: the repository reports sql.ErrNoRows for a country without SWIFT codes
+	mockRepo.On("GetByCountryISO2", countryISO2).Return(nil, sql.ErrNoRows)
 
 	req, _ := http.NewRequest("GET", "/swift-codes/country/"+countryISO2, nil)
 	recorder := httptest.NewRecorder()
@@ -131,7 +133,8 @@ func TestGetSwiftCodesByCountry_NoSwiftCodesFound(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify the error message in the response
-	assert.Contains(t, response["error"], "No SWIFT codes found for the given country")
+	assert.Equal(t, "COUNTRY_NOT_FOUND", response["code"])
+	assert.Contains(t, response["detail"], "No SWIFT codes found for the given country")
 
 	mockRepo.AssertExpectations(t)
 }
@@ -157,6 +160,7 @@ func TestGetSwiftCodesByCountry_RepositoryError(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["error"], "Error fetching SWIFT codes")
+	assert.Equal(t, "INTERNAL_ERROR", response["code"])
+	assert.Contains(t, response["detail"], "Error fetching SWIFT codes")
 	mockRepo.AssertExpectations(t)
 }
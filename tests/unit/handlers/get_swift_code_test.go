@@ -70,7 +70,7 @@ func TestGetSwiftCodeDetails_Headquarter(t *testing.T) {
 	assert.Equal(t, swiftCode, response["swiftCode"])
 	assert.Equal(t, "Test Bank", response["bankName"])
 	assert.Equal(t, "US", response["countryISO2"])
-	assert.Equal(t, "United States", response["countryName"])
+	assert.Equal(t, "UNITED STATES", response["countryName"])
 	assert.True(t, response["isHeadquarter"].(bool))
 
 	// Verify branches
@@ -84,7 +84,7 @@ func TestGetSwiftCodeDetails_Headquarter(t *testing.T) {
 	assert.Equal(t, "BANKUS33ABC", branchData["swiftCode"])
 	assert.Equal(t, "Test Bank Branch", branchData["bankName"])
 	assert.Equal(t, "US", branchData["countryISO2"])
-	assert.Equal(t, "United States", branchData["countryName"])
+	assert.Equal(t, "UNITED STATES", branchData["countryName"])
 	assert.Equal(t, "456 Test Ave", branchData["address"])
 
 	mockRepo.AssertExpectations(t)
@@ -128,7 +128,7 @@ func TestGetSwiftCodeDetails_Headquarter_NoBranches(t *testing.T) {
 	assert.Equal(t, swiftCode, response["swiftCode"])
 	assert.Equal(t, "Test Bank", response["bankName"])
 	assert.Equal(t, "US", response["countryISO2"])
-	assert.Equal(t, "United States", response["countryName"])
+	assert.Equal(t, "UNITED STATES", response["countryName"])
 	assert.True(t, response["isHeadquarter"].(bool))
 
 	// Should return an empty list of branches
@@ -159,6 +159,9 @@ func TestGetSwiftCodeDetails_Branch(t *testing.T) {
 		IsHeadquarter: false,
 		Address:       "456 Test Ave",
 		HeadquarterID: &headquarterID,
+		CodeType:      "BIC11",
+		TownName:      "NEW YORK",
+		TimeZone:      "America/New_York",
 	}
 
 	mockRepo.On("GetBySwiftCode", swiftCode).Return(mockSwift, nil)
@@ -176,11 +179,115 @@ func TestGetSwiftCodeDetails_Branch(t *testing.T) {
 	assert.Equal(t, swiftCode, response["swiftCode"])
 	assert.Equal(t, "Test Bank Branch", response["bankName"])
 	assert.Equal(t, "US", response["countryISO2"])
-	assert.Equal(t, "United States", response["countryName"])
+	assert.Equal(t, "UNITED STATES", response["countryName"])
 	assert.False(t, response["isHeadquarter"].(bool))
+	assert.Equal(t, "BIC11", response["codeType"])
+	assert.Equal(t, "NEW YORK", response["townName"])
+	assert.Equal(t, "America/New_York", response["timeZone"])
 
 	_, branchesExist := response["branches"]
 	assert.False(t, branchesExist, "branches field should NOT exist for branch SWIFT code")
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetSwiftCodeDetails_CaseInsensitiveBIC8(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(&models.SwiftCode{
+		ID:            1,
+		SwiftCode:     "BANKUS33XXX",
+		BankName:      "Test Bank",
+		CountryISO2:   "US",
+		IsHeadquarter: true,
+	}, nil)
+	mockRepo.On("GetBranchesByHeadquarter", "BANKUS33XXX").Return([]models.SwiftCode{}, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/bankus33", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "BANKUS33XXX", response["swiftCode"])
+	_, fallbackExists := response["fallback"]
+	assert.False(t, fallbackExists, "A BIC8 is the primary office itself, not a fallback")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSwiftCodeDetails_FallbackToHeadquarter(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ZZZ").Return(nil, nil)
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(&models.SwiftCode{
+		ID:            1,
+		SwiftCode:     "BANKUS33XXX",
+		BankName:      "Test Bank",
+		CountryISO2:   "US",
+		IsHeadquarter: true,
+	}, nil)
+	mockRepo.On("GetBranchesByHeadquarter", "BANKUS33XXX").Return([]models.SwiftCode{}, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/BankUS33zzz?fallback=true", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "BANKUS33XXX", response["swiftCode"])
+	assert.Equal(t, true, response["fallback"])
+	assert.Equal(t, "BANKUS33ZZZ", response["requestedSwiftCode"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSwiftCodeDetails_NoFallbackByDefault(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ZZZ").Return(nil, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/BANKUS33ZZZ", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "GetBySwiftCode", 1)
+}
+
+func TestGetSwiftCodeDetails_FallbackHeadquarterMissing(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ZZZ").Return(nil, nil)
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(nil, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/BANKUS33ZZZ?fallback=true", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSwiftCodeDetails_InvalidFallback(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/BANKUS33ZZZ?fallback=maybe", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertNotCalled(t, "GetBySwiftCode", "BANKUS33ZZZ")
+}
@@ -0,0 +1,279 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const idempotencyWindow = 24 * time.Hour
+
+func sendWithIdempotencyKey(router *gin.Engine, method, path, key string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func idempotentSwiftCode() map[string]interface{} {
+	return map[string]interface{}{
+		"swiftCode":     "NEWBUS33XXX",
+		"bankName":      "New Bank",
+		"address":       "123 New St",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": true,
+	}
+}
+
+// TestIdempotency_ReplaysResponse - a retry with the same key and body gets the stored response without being processed again
+func TestIdempotency_ReplaysResponse(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil).Once()
+	mockRepo.On("InsertSwiftCode", mock.Anything).Return(nil).Once()
+	mockRepo.On("AssignBranchesToHeadquarter", "NEWBUS33XXX").Return(nil).Once()
+
+	var stored models.IdempotencyKey
+	keys.On("ReserveKey", "retry-1", mock.AnythingOfType("string"), idempotencyWindow).Return(nil, nil).Once().
+		Run(func(args mock.Arguments) { stored.RequestHash = args.String(1) })
+	keys.On("SaveResponse", "retry-1", http.StatusOK, "application/json; charset=utf-8", "", mock.Anything).Return(nil).Once().
+		Run(func(args mock.Arguments) {
+			status := args.Int(1)
+			stored.StatusCode, stored.ContentType, stored.ResponseBody = &status, args.String(2), args.Get(4).([]byte)
+		})
+
+	first := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Empty(t, first.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), string(stored.ResponseBody))
+
+	keys.On("ReserveKey", "retry-1", stored.RequestHash, idempotencyWindow).Return(&stored, nil).Once()
+
+	retry := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	mockRepo.AssertExpectations(t)
+	keys.AssertExpectations(t)
+}
+
+// TestIdempotency_KeyReusedForDifferentRequest - a key stored for another body is rejected with 422
+func TestIdempotency_KeyReusedForDifferentRequest(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	status := http.StatusOK
+	keys.On("ReserveKey", "retry-1", mock.AnythingOfType("string"), idempotencyWindow).
+		Return(&models.IdempotencyKey{Key: "retry-1", RequestHash: "another request", StatusCode: &status}, nil)
+
+	recorder := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, handlers.CodeIdempotencyKeyReused, decodeProblem(t, recorder).Code)
+	mockRepo.AssertNotCalled(t, "InsertSwiftCode", mock.Anything)
+}
+
+// TestIdempotency_RequestInProgress - a retry arriving before the first request completes gets 409
+func TestIdempotency_RequestInProgress(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	keys.On("ReserveKey", "retry-1", mock.AnythingOfType("string"), idempotencyWindow).
+		Return(nil, nil).Once().
+		Run(func(args mock.Arguments) {
+			keys.On("ReserveKey", "retry-1", args.String(1), idempotencyWindow).
+				Return(&models.IdempotencyKey{Key: "retry-1", RequestHash: args.String(1)}, nil)
+		})
+	keys.On("ReleaseKey", "retry-1").Return(nil)
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Run(func(mock.Arguments) {
+		recorder := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+		assert.Equal(t, handlers.CodeIdempotencyKeyInProgress, decodeProblem(t, recorder).Code)
+	}).Return(nil, assert.AnError)
+
+	recorder := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestIdempotency_ServerErrorReleasesKey - a failed request is not stored, so that it can be retried
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	keys.On("ReserveKey", "retry-1", mock.AnythingOfType("string"), idempotencyWindow).Return(nil, nil)
+	keys.On("ReleaseKey", "retry-1").Return(nil).Once()
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, assert.AnError)
+
+	recorder := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	keys.AssertExpectations(t)
+	keys.AssertNotCalled(t, "SaveResponse", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestIdempotency_PanicReleasesKey - a panicking handler does not leave the key reserved
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	keys.On("ReserveKey", "retry-1", mock.AnythingOfType("string"), idempotencyWindow).Return(nil, nil)
+	keys.On("ReleaseKey", "retry-1").Return(nil).Once()
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Run(func(mock.Arguments) { panic("boom") })
+
+	recorder := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", "retry-1", idempotentSwiftCode())
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	keys.AssertExpectations(t)
+}
+
+// TestIdempotency_StoresClientErrors - a 4xx response is stored and replayed like a success
+func TestIdempotency_StoresClientErrors(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	keys.On("ReserveKey", "delete-1", mock.AnythingOfType("string"), idempotencyWindow).Return(nil, nil)
+	keys.On("SaveResponse", "delete-1", http.StatusNotFound, "application/problem+json", "", mock.Anything).Return(nil).Once()
+	mockRepo.On("GetBySwiftCode", "NONEXI33XXX").Return(nil, nil)
+
+	recorder := sendWithIdempotencyKey(router, "DELETE", "/v1/swift-codes/NONEXI33XXX", "delete-1", nil)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	keys.AssertExpectations(t)
+}
+
+// TestIdempotency_InvalidKey - keys with spaces, longer than 255 characters or not ASCII are rejected
+func TestIdempotency_InvalidKey(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	for _, key := range []string{"has space", string(bytes.Repeat([]byte("k"), 256)), "klucz-żółty"} {
+		recorder := sendWithIdempotencyKey(router, "POST", "/v1/swift-codes", key, idempotentSwiftCode())
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, key)
+		assert.Equal(t, handlers.CodeInvalidIdempotencyKey, decodeProblem(t, recorder).Code)
+	}
+	keys.AssertNotCalled(t, "ReserveKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestIdempotency_IgnoredWithoutKeyAndOnReads - requests without the header and GET requests are not tracked
+func TestIdempotency_IgnoredWithoutKeyAndOnReads(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil)
+	mockRepo.On("InsertSwiftCode", mock.Anything).Return(nil)
+	mockRepo.On("AssignBranchesToHeadquarter", "NEWBUS33XXX").Return(nil)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", idempotentSwiftCode())
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = sendWithIdempotencyKey(router, "GET", "/v1/swift-codes/NEWBUS33XXX", "read-1", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	keys.AssertNotCalled(t, "ReserveKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestIdempotency_ReplaysETag - the ETag of an update is stored and replayed with the response
+func TestIdempotency_ReplaysETag(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	expected := versionedBranch(3)
+	expected.Address = "789 New Ave"
+	updated := versionedBranch(4)
+	updated.Address = "789 New Ave"
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(versionedBranch(3), nil).Once()
+	mockRepo.On("UpdateSwiftCode", expected).Return(nil).Once()
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(updated, nil).Once()
+
+	var stored models.IdempotencyKey
+	keys.On("ReserveKey", "patch-1", mock.AnythingOfType("string"), idempotencyWindow).Return(nil, nil).Once().
+		Run(func(args mock.Arguments) { stored.RequestHash = args.String(1) })
+	keys.On("SaveResponse", "patch-1", http.StatusOK, "application/json; charset=utf-8", `"4"`, mock.Anything).Return(nil).Once().
+		Run(func(args mock.Arguments) {
+			status := args.Int(1)
+			stored.StatusCode, stored.ContentType, stored.ETag, stored.ResponseBody = &status, args.String(2), args.String(3), args.Get(4).([]byte)
+		})
+
+	patch := map[string]interface{}{"address": "789 New Ave"}
+	first := sendIdempotentIfMatch(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", "patch-1", `"3"`, patch)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, `"4"`, first.Header().Get("ETag"))
+
+	keys.On("ReserveKey", "patch-1", stored.RequestHash, idempotencyWindow).Return(&stored, nil).Once()
+
+	retry := sendIdempotentIfMatch(router, "PATCH", "/v1/swift-codes/BANKUS33ABC", "patch-1", `"3"`, patch)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, `"4"`, retry.Header().Get("ETag"))
+
+	mockRepo.AssertExpectations(t)
+	keys.AssertExpectations(t)
+}
+
+// TestIdempotency_IfMatchIsPartOfRequest - reusing a key with another If-Match is a different request
+func TestIdempotency_IfMatchIsPartOfRequest(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	keys := new(mocks.MockIdempotencyKeyRepository)
+	router := setupRouter(mockRepo, handlers.Idempotency(keys, idempotencyWindow))
+
+	status := http.StatusOK
+	stored := &models.IdempotencyKey{Key: "delete-1", StatusCode: &status, ContentType: "application/json; charset=utf-8", ResponseBody: []byte(`{}`)}
+	keys.On("ReserveKey", "delete-1", mock.AnythingOfType("string"), idempotencyWindow).Return(stored, nil).
+		Run(func(args mock.Arguments) {
+			if stored.RequestHash == "" {
+				stored.RequestHash = args.String(1)
+			}
+		})
+
+	recorder := sendIdempotentIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33ABC", "delete-1", `"3"`, nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "true", recorder.Header().Get(handlers.IdempotentReplayedHeader))
+
+	recorder = sendIdempotentIfMatch(router, "DELETE", "/v1/swift-codes/BANKUS33ABC", "delete-1", `"4"`, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, handlers.CodeIdempotencyKeyReused, decodeProblem(t, recorder).Code)
+	mockRepo.AssertNotCalled(t, "DeleteSwiftCode", mock.Anything, mock.Anything)
+}
+
+func sendIdempotentIfMatch(router *gin.Engine, method, path, key, ifMatch string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, key)
+	req.Header.Set("If-Match", ifMatch)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
@@ -0,0 +1,191 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+const importCSV = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+	"PL,ABCDPLPWXXX,BIC11,Bank HQ,Main Street 1,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPW001,BIC11,Branch Bank,Branch Street 22,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"PL,ABCDPLPW002,BIC11,New Branch,New Street 4,WARSZAWA,POLAND,Europe/Warsaw\n" +
+	"DE,ABCDPLPW003,BIC11,Wrong Country,New Street 5,WARSZAWA,GERMANY,Europe/Warsaw\n"
+
+func sendImport(router *gin.Engine, query string, content string) *httptest.ResponseRecorder {
+	return sendImportForm(router, query, content, nil)
+}
+
+// sendImportForm uploads content together with extra form fields.
+func sendImportForm(router *gin.Engine, query string, content string, fields map[string]string) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if content != "" {
+		part, _ := writer.CreateFormFile("file", "swift_codes.csv")
+		part.Write([]byte(content))
+	}
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/v1/imports"+query, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func setupImportRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/v1/imports", handlers.NewImportHandler(db).ImportSwiftCodes)
+	return router, mock
+}
+
+func TestImportSwiftCodes_DryRun(t *testing.T) {
+	router, mock := setupImportRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone FROM swift_codes;")).
+		WillReturnRows(sqlmock.NewRows([]string{"swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "code_type", "town_name", "time_zone"}).
+			AddRow("ABCDPLPWXXX", "Bank HQ", "Main Street 1", "PL", "POLAND", true, "BIC11", "WARSZAWA", "Europe/Warsaw").
+			AddRow("ABCDPLPW001", "Branch Bank", "Branch Street 2", "PL", "POLAND", false, "BIC11", "WARSZAWA", "Europe/Warsaw").
+			AddRow("EFGHPLPWXXX", "Other Bank", "Old Street 3", "PL", "POLAND", true, "BIC11", "WARSZAWA", "Europe/Warsaw"))
+	mock.ExpectRollback()
+
+	recorder := sendImport(router, "?dryRun=true", importCSV)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result handlers.ImportResult
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 4, result.TotalRecords)
+	assert.Equal(t, []string{"ABCDPLPW002"}, result.InsertedSwiftCodes)
+	assert.Equal(t, []string{"ABCDPLPW001"}, result.UpdatedSwiftCodes)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 1, result.Rejected)
+	assert.Equal(t, 5, result.RejectedRecords[0].Line)
+	assert.Equal(t, "ABCDPLPW003", result.RejectedRecords[0].SwiftCode)
+	assert.Contains(t, result.RejectedRecords[0].Reason, "does not match country code")
+
+	// Nothing is written and codes missing from the file (EFGHPLPWXXX) are not removed.
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportSwiftCodes_MissingFile(t *testing.T) {
+	router, mock := setupImportRouter(t)
+
+	recorder := sendImport(router, "", "")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeValidationFailed, problem.Code)
+	assert.Equal(t, "file", problem.Errors[0].Field)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportSwiftCodes_MalformedCSV(t *testing.T) {
+	router, mock := setupImportRouter(t)
+
+	recorder := sendImport(router, "", "COUNTRY ISO2 CODE,SWIFT CODE\nPL,\"ABCDPLPWXXX\n")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, handlers.CodeInvalidImportFile, decodeProblem(t, recorder).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportSwiftCodes_InvalidDryRun(t *testing.T) {
+	router, _ := setupImportRouter(t)
+
+	recorder := sendImport(router, "?dryRun=perhaps", importCSV)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, handlers.CodeInvalidQuery, decodeProblem(t, recorder).Code)
+}
+
+func TestImportSwiftCodes_DetectsEncoding(t *testing.T) {
+	router, mock := setupImportRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone FROM swift_codes;")).
+		WillReturnRows(sqlmock.NewRows([]string{"swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "code_type", "town_name", "time_zone"}).
+			AddRow("ABCDPLPWXXX", "Bank Śląski", "Plac Grzybowski 1", "PL", "POLAND", true, "BIC11", "ŁÓDŹ", "Europe/Warsaw"))
+	mock.ExpectRollback()
+
+	// The stored record matches the file once it is read as Windows-1250.
+	content := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,ABCDPLPWXXX,BIC11,Bank \x8Cl\xB9ski,Plac Grzybowski 1,\xA3\xD3D\x8F,POLAND,Europe/Warsaw\n"
+	recorder := sendImport(router, "?dryRun=true", content)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result handlers.ImportResult
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, services.EncodingWindows1250, result.Encoding)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportSwiftCodes_InvalidEncoding(t *testing.T) {
+	router, mock := setupImportRouter(t)
+
+	recorder := sendImportForm(router, "", importCSV, map[string]string{"encoding": "ebcdic"})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeValidationFailed, problem.Code)
+	assert.Equal(t, "encoding", problem.Errors[0].Field)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportSwiftCodes_DuplicatePolicy(t *testing.T) {
+	router, mock := setupImportRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone FROM swift_codes;")).
+		WillReturnRows(sqlmock.NewRows([]string{"swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "code_type", "town_name", "time_zone"}))
+	mock.ExpectRollback()
+
+	content := importCSV + "PL,ABCDPLPW001,BIC11,Renamed Branch,Branch Street 22,WARSZAWA,POLAND,Europe/Warsaw\n"
+	recorder := sendImportForm(router, "?dryRun=true", content, map[string]string{"duplicates": "reject"})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result handlers.ImportResult
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, services.DuplicateReject, result.DuplicatePolicy)
+	assert.Equal(t, []services.DuplicateRecord{{Line: 6, FirstLine: 3, SwiftCode: "ABCDPLPW001", Kind: services.DuplicateConflicting}}, result.Duplicates)
+	assert.Equal(t, []string{"ABCDPLPW001"}, result.Discarded)
+	assert.Equal(t, []string{"ABCDPLPWXXX", "ABCDPLPW002"}, result.InsertedSwiftCodes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportSwiftCodes_InvalidDuplicatePolicy(t *testing.T) {
+	router, _ := setupImportRouter(t)
+
+	recorder := sendImportForm(router, "", importCSV, map[string]string{"duplicates": "merge"})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeValidationFailed, problem.Code)
+	assert.Equal(t, "duplicates", problem.Errors[0].Field)
+}
@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHeadquarterLinkIssues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.GET("/v1/integrity/headquarter-links", handler.GetHeadquarterLinkIssues)
+
+	headquarterID := int64(4)
+	linked := "BANKCA66XXX"
+	mockRepo.On("FindHeadquarterLinkIssues").Return([]models.HeadquarterLinkIssue{
+		{
+			SwiftCode:       "BANKCA77AAA",
+			HeadquarterID:   &headquarterID,
+			LinkedSwiftCode: &linked,
+			Problem:         models.LinkIssueWrongInstitution,
+		},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/integrity/headquarter-links", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, float64(1), response["count"])
+	issues := response["issues"].([]interface{})
+	issue := issues[0].(map[string]interface{})
+	assert.Equal(t, "BANKCA77AAA", issue["swiftCode"])
+	assert.Equal(t, float64(4), issue["headquarterId"])
+	assert.Equal(t, "BANKCA66XXX", issue["linkedSwiftCode"])
+	assert.Nil(t, issue["expectedHeadquarter"])
+	assert.Equal(t, "WRONG_INSTITUTION", issue["problem"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetHeadquarterLinkIssues_RepositoryError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.GET("/v1/integrity/headquarter-links", handler.GetHeadquarterLinkIssues)
+
+	mockRepo.On("FindHeadquarterLinkIssues").Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest("GET", "/v1/integrity/headquarter-links", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListSwiftCodes_FiltersAndNextLink(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	isHeadquarter := false
+	expectedFilter := repositories.SwiftCodeFilter{
+		CountryISO2:   "US",
+		BankName:      "test",
+		IsHeadquarter: &isHeadquarter,
+		CodePrefix:    "BANK",
+		SortBy:        "bankName",
+		Descending:    true,
+		Limit:         3, // page size + 1
+	}
+	mockRepo.On("ListSwiftCodes", expectedFilter).Return([]models.SwiftCode{
+		{SwiftCode: "BANKUS33DEF", BankName: "Test Bank Branch B", CountryISO2: "US", CountryName: "United States"},
+		{SwiftCode: "BANKUS33ABC", BankName: "Test Bank Branch A", CountryISO2: "US", CountryName: "United States"},
+		{SwiftCode: "BANKUS44ABC", BankName: "Test Bank Another", CountryISO2: "US", CountryName: "United States"},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes?country=us&bankName=test&isHeadquarter=false&prefix=bank&sort=-bankName&limit=2", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	swiftCodes := response["swiftCodes"].([]interface{})
+	assert.Len(t, swiftCodes, 2)
+	assert.Equal(t, "BANKUS33DEF", swiftCodes[0].(map[string]interface{})["swiftCode"])
+	assert.Equal(t, "BANKUS33ABC", swiftCodes[1].(map[string]interface{})["swiftCode"])
+
+	cursor, ok := response["nextCursor"].(string)
+	assert.True(t, ok, "nextCursor should be set when more rows exist")
+
+	next, err := url.Parse(response["next"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/swift-codes", next.Path)
+	assert.Equal(t, cursor, next.Query().Get("cursor"))
+	assert.Equal(t, "-bankName", next.Query().Get("sort"))
+
+	mockRepo.AssertExpectations(t)
+
+	// Following the link continues after the last returned row.
+	followRepo := new(mocks.MockSwiftCodeRepository)
+	followRouter := setupRouter(followRepo)
+	followRepo.On("ListSwiftCodes", mock.MatchedBy(func(filter repositories.SwiftCodeFilter) bool {
+		return filter.After != nil &&
+			filter.After.SortValue == "Test Bank Branch A" &&
+			filter.After.SwiftCode == "BANKUS33ABC"
+	})).Return([]models.SwiftCode{}, nil)
+
+	req, _ = http.NewRequest("GET", next.String(), nil)
+	recorder = httptest.NewRecorder()
+	followRouter.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Nil(t, response["next"])
+	followRepo.AssertExpectations(t)
+}
+
+func TestListSwiftCodes_InvalidParameters(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	for _, query := range []string{"sort=address", "limit=0", "limit=1000", "isHeadquarter=maybe", "cursor=not-a-cursor"} {
+		req, _ := http.NewRequest("GET", "/v1/swift-codes?"+query, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+
+	mockRepo.AssertNotCalled(t, "ListSwiftCodes", mock.Anything)
+}
+
+func TestListSwiftCodes_RepositoryError(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("ListSwiftCodes", mock.Anything).Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLookupSwiftCodes_ResultsInInputOrder(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	// Duplicates and the BIC8 form of a code are queried once.
+	mockRepo.On("GetBySwiftCodes", []string{"BANKUS33ABC", "BANKUS33XXX", "BANKDE44ZZZ"}).Return([]models.SwiftCode{
+		{SwiftCode: "BANKUS33XXX", BankName: "Test Bank", CountryISO2: "US", CountryName: "United States", IsHeadquarter: true},
+		{SwiftCode: "BANKUS33ABC", BankName: "Test Bank Branch", CountryISO2: "US", CountryName: "United States"},
+	}, nil)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/lookup", map[string]interface{}{
+		"swiftCodes": []string{"bankus33abc", "BANK", "BANKUS33", "BANKDE44ZZZ", "BANKUS33XXX"},
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Results, 5)
+
+	statuses := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		statuses = append(statuses, result["status"].(string))
+	}
+	assert.Equal(t, []string{
+		handlers.LookupStatusFound, handlers.LookupStatusInvalid, handlers.LookupStatusFound,
+		handlers.LookupStatusNotFound, handlers.LookupStatusFound,
+	}, statuses)
+
+	assert.Equal(t, "BANKUS33ABC", response.Results[0]["swiftCode"])
+	assert.Equal(t, "Test Bank Branch", response.Results[0]["data"].(map[string]interface{})["bankName"])
+	assert.Equal(t, "Invalid SWIFT code length. Must be 8 or 11 characters.", response.Results[1]["error"])
+	assert.Equal(t, "BANKUS33", response.Results[2]["swiftCode"])
+	assert.Equal(t, "BANKUS33XXX", response.Results[2]["data"].(map[string]interface{})["swiftCode"])
+	assert.Equal(t, "UNITED STATES", response.Results[2]["data"].(map[string]interface{})["countryName"])
+	_, hasData := response.Results[3]["data"]
+	assert.False(t, hasData)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLookupSwiftCodes_OnlyInvalidCodes(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/lookup", map[string]interface{}{
+		"swiftCodes": []string{"12345678"},
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), handlers.LookupStatusInvalid)
+	mockRepo.AssertNotCalled(t, "GetBySwiftCodes", mock.Anything)
+}
+
+func TestLookupSwiftCodes_TooManyCodes(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	codes := strings.Split(strings.Repeat("BANKUS33XXX,", 1001), ",")[:1001]
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/lookup", map[string]interface{}{
+		"swiftCodes": codes,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), handlers.CodeValidationFailed)
+	mockRepo.AssertNotCalled(t, "GetBySwiftCodes", mock.Anything)
+}
+
+func TestLookupSwiftCodes_MissingCodes(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/lookup", map[string]interface{}{})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	mockRepo.AssertNotCalled(t, "GetBySwiftCodes", mock.Anything)
+}
+
+func TestLookupSwiftCodes_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCodes", []string{"BANKUS33XXX"}).Return(nil, assert.AnError)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/lookup", map[string]interface{}{
+		"swiftCodes": []string{"BANKUS33XXX"},
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -3,6 +3,7 @@ package unit
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,8 +11,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mroczekDNF/swift-api/internal/handlers"
 	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
 	"github.com/mroczekDNF/swift-api/tests/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAddSwiftCode_Success(t *testing.T) {
@@ -23,25 +26,31 @@ func TestAddSwiftCode_Success(t *testing.T) {
 	router.POST("/v1/swift-codes", handler.AddSwiftCode)
 
 	requestBody := map[string]interface{}{
-		"swiftCode":     "NEWBANKXYY",
+		"swiftCode":     "NEWBUS33XXX",
 		"bankName":      "New Bank",
 		"address":       "123 New St",
 		"countryISO2":   "US",
 		"countryName":   "United States",
 		"isHeadquarter": true,
+		"townName":      "NEW YORK",
+		"timeZone":      "America/New_York",
 	}
 
 	expectedSwiftCode := &models.SwiftCode{
-		SwiftCode:     "NEWBANKXYY",
+		SwiftCode:     "NEWBUS33XXX",
 		BankName:      "New Bank",
 		Address:       "123 New St",
 		CountryISO2:   "US",
-		CountryName:   "United States",
+		CountryName:   "UNITED STATES",
 		IsHeadquarter: true,
+		CodeType:      "BIC11",
+		TownName:      "NEW YORK",
+		TimeZone:      "America/New_York",
 	}
 
-	mockRepo.On("GetBySwiftCode", "NEWBANKXYY").Return(nil, nil)
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil)
 	mockRepo.On("InsertSwiftCode", expectedSwiftCode).Return(nil)
+	mockRepo.On("AssignBranchesToHeadquarter", "NEWBUS33XXX").Return(nil)
 
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest("POST", "/v1/swift-codes", bytes.NewBuffer(body))
@@ -62,6 +71,33 @@ func TestAddSwiftCode_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestAddSwiftCode_BIC8 - an 8-character code is stored in its 11-character form
+func TestAddSwiftCode_BIC8(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil)
+	mockRepo.On("InsertSwiftCode", mock.MatchedBy(func(swift *models.SwiftCode) bool {
+		return swift.SwiftCode == "NEWBUS33XXX" && swift.CodeType == "BIC8" && swift.IsHeadquarter
+	})).Return(nil)
+	mockRepo.On("AssignBranchesToHeadquarter", "NEWBUS33XXX").Return(nil)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+		"swiftCode":     "newbus33",
+		"bankName":      "New Bank",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": true,
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestAddSwiftCode_InvalidRequestStructure(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
@@ -87,7 +123,7 @@ func TestAddSwiftCode_InvalidRequestStructure(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response["error"], "Invalid request structure")
+	assert.Equal(t, "VALIDATION_FAILED", response["code"])
 	mockRepo.AssertExpectations(t)
 }
 
@@ -100,7 +136,7 @@ func TestAddSwiftCode_AlreadyExists(t *testing.T) {
 	router.POST("/v1/swift-codes", handler.AddSwiftCode)
 
 	requestBody := map[string]interface{}{
-		"swiftCode":     "EXISTBANKXX",
+		"swiftCode":     "EXISGB22ABC",
 		"bankName":      "Existing Bank",
 		"address":       "Old Address",
 		"countryISO2":   "GB",
@@ -109,7 +145,7 @@ func TestAddSwiftCode_AlreadyExists(t *testing.T) {
 	}
 
 	existingSwiftCode := &models.SwiftCode{
-		SwiftCode:     "EXISTBANKXX",
+		SwiftCode:     "EXISGB22ABC",
 		BankName:      "Existing Bank",
 		Address:       "Old Address",
 		CountryISO2:   "GB",
@@ -117,7 +153,7 @@ func TestAddSwiftCode_AlreadyExists(t *testing.T) {
 		IsHeadquarter: false,
 	}
 
-	mockRepo.On("GetBySwiftCode", "EXISTBANKXX").Return(existingSwiftCode, nil)
+	mockRepo.On("GetBySwiftCode", "EXISGB22ABC").Return(existingSwiftCode, nil)
 
 	body, _ := json.Marshal(requestBody)
 	req, _ := http.NewRequest("POST", "/v1/swift-codes", bytes.NewBuffer(body))
@@ -132,8 +168,158 @@ func TestAddSwiftCode_AlreadyExists(t *testing.T) {
 	err := json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 
-	assert.Contains(t, response, "error")
-	assert.Equal(t, "SWIFT code already exists in the database", response["error"])
+	assert.Equal(t, "DUPLICATE_SWIFT_CODE", response["code"])
+	assert.Equal(t, "SWIFT code already exists in the database", response["detail"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAddSwiftCode_ConcurrentInsert - a code inserted by a concurrent request after the existence check is reported as 409
+func TestAddSwiftCode_ConcurrentInsert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	requestBody := map[string]interface{}{
+		"swiftCode":     "RACEUS33XXX",
+		"bankName":      "Race Bank",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": true,
+	}
+
+	mockRepo.On("GetBySwiftCode", "RACEUS33XXX").Return(nil, nil)
+	mockRepo.On("InsertSwiftCode", mock.Anything).Return(fmt.Errorf("%w: RACEUS33XXX", repositories.ErrDuplicateSwiftCode))
+
+	body, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/v1/swift-codes", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
 
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Equal(t, handlers.CodeDuplicateSwiftCode, decodeProblem(t, recorder).Code)
 	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "AssignBranchesToHeadquarter", mock.Anything)
+}
+
+func TestAddSwiftCode_InvalidSwiftCodeFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	for _, swiftCode := range []string{"12345678", "BANKUS33X"} {
+		recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+			"swiftCode":     swiftCode,
+			"bankName":      "Test Bank",
+			"countryISO2":   "US",
+			"countryName":   "United States",
+			"isHeadquarter": false,
+		})
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, swiftCode)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "VALIDATION_FAILED", response["code"])
+		fieldErrors := response["errors"].([]interface{})
+		assert.Equal(t, "swiftCode", fieldErrors[0].(map[string]interface{})["field"])
+	}
+
+	mockRepo.AssertNotCalled(t, "InsertSwiftCode", mock.Anything)
+}
+
+func TestAddSwiftCode_HeadquarterMustEndWithXXX(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+		"swiftCode":     "BANKUS33ABC",
+		"bankName":      "Test Bank",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": true,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	fieldErrors := response["errors"].([]interface{})
+	assert.Len(t, fieldErrors, 1)
+	assert.Equal(t, "isHeadquarter", fieldErrors[0].(map[string]interface{})["field"])
+
+	mockRepo.AssertNotCalled(t, "InsertSwiftCode", mock.Anything)
+}
+
+func TestAddSwiftCode_CountryMustMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+		"swiftCode":     "BANKUS33XXX",
+		"bankName":      "Test Bank",
+		"countryISO2":   "PL",
+		"countryName":   "Narnia",
+		"isHeadquarter": true,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	fields := make([]string, 0)
+	for _, fieldError := range response["errors"].([]interface{}) {
+		fields = append(fields, fieldError.(map[string]interface{})["field"].(string))
+	}
+	assert.ElementsMatch(t, []string{"swiftCode", "countryName"}, fields)
+
+	mockRepo.AssertNotCalled(t, "InsertSwiftCode", mock.Anything)
+}
+
+func TestAddSwiftCode_UnknownCountry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	router.POST("/v1/swift-codes", handler.AddSwiftCode)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+		"swiftCode":     "BANKQQ33XXX",
+		"bankName":      "Test Bank",
+		"countryISO2":   "QQ",
+		"countryName":   "Narnia",
+		"isHeadquarter": true,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	fieldErrors := response["errors"].([]interface{})
+	assert.Len(t, fieldErrors, 1)
+	assert.Equal(t, "countryISO2", fieldErrors[0].(map[string]interface{})["field"])
+
+	mockRepo.AssertNotCalled(t, "InsertSwiftCode", mock.Anything)
 }
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func batchItem(swiftCode string, isHeadquarter bool) map[string]interface{} {
+	return map[string]interface{}{
+		"swiftCode":     swiftCode,
+		"bankName":      "New Bank",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": isHeadquarter,
+	}
+}
+
+type batchResponse struct {
+	Created int                        `json:"created"`
+	Failed  int                        `json:"failed"`
+	Results []handlers.BatchItemResult `json:"results"`
+}
+
+func TestAddSwiftCodesBatch_PartialSuccess(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	// The branch comes before its headquarter; linking it is left to the headquarter insert.
+	mockRepo.On("GetBySwiftCode", "NEWBUS33ABC").Return(nil, nil)
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil)
+	mockRepo.On("GetBySwiftCode", "EXISUS33XXX").Return(&models.SwiftCode{ID: 7, SwiftCode: "EXISUS33XXX"}, nil)
+	mockRepo.On("InsertSwiftCode", mock.MatchedBy(func(swift *models.SwiftCode) bool {
+		return swift.SwiftCode == "NEWBUS33ABC" && swift.HeadquarterID == nil
+	})).Return(nil).Once()
+	mockRepo.On("InsertSwiftCode", mock.MatchedBy(func(swift *models.SwiftCode) bool {
+		return swift.SwiftCode == "NEWBUS33XXX" && swift.CountryName == "UNITED STATES"
+	})).Return(nil).Once()
+	mockRepo.On("AssignBranchesToHeadquarter", "NEWBUS33XXX").Return(nil)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/batch", map[string]interface{}{
+		"swiftCodes": []interface{}{
+			batchItem("newbus33abc", false),
+			map[string]interface{}{"swiftCode": "BROKUS33XXX"},
+			batchItem("NEWBUS33XXX", true),
+			batchItem("EXISUS33XXX", true),
+			"not an object",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response batchResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 3, response.Failed)
+
+	statuses := make([]string, 0, len(response.Results))
+	for i, result := range response.Results {
+		assert.Equal(t, i, result.Index)
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{
+		handlers.BatchStatusCreated, handlers.BatchStatusInvalid, handlers.BatchStatusCreated,
+		handlers.BatchStatusDuplicate, handlers.BatchStatusInvalid,
+	}, statuses)
+	assert.Equal(t, "NEWBUS33ABC", response.Results[0].SwiftCode)
+	assert.Len(t, response.Results[1].Errors, 4, "bankName, countryISO2, countryName and isHeadquarter are missing")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddSwiftCodesBatch_AllOrNothingRejectsInvalidItem(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	invalid := batchItem("NEWBUS33XXX", false)
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/batch", map[string]interface{}{
+		"swiftCodes":   []interface{}{batchItem("NEWBUS33ABC", false), invalid},
+		"allOrNothing": true,
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeBatchRejected, problem.Code)
+	assert.Equal(t, "No SWIFT codes were saved: 1 of 2 items failed", problem.Detail)
+	assert.Len(t, problem.Errors, 1)
+	assert.Equal(t, "swiftCodes[1].isHeadquarter", problem.Errors[0].Field)
+
+	mockRepo.AssertNotCalled(t, "GetBySwiftCode", mock.Anything)
+	mockRepo.AssertNotCalled(t, "InsertSwiftCode", mock.Anything)
+}
+
+func TestAddSwiftCodesBatch_AllOrNothingRejectsDuplicate(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil)
+	mockRepo.On("GetBySwiftCode", "EXISUS33XXX").Return(&models.SwiftCode{ID: 7, SwiftCode: "EXISUS33XXX"}, nil)
+	mockRepo.On("InsertSwiftCode", mock.Anything).Return(nil)
+	mockRepo.On("AssignBranchesToHeadquarter", "NEWBUS33XXX").Return(nil)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/batch", map[string]interface{}{
+		"swiftCodes":   []interface{}{batchItem("NEWBUS33XXX", true), batchItem("EXISUS33XXX", true)},
+		"allOrNothing": true,
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeBatchRejected, problem.Code)
+	assert.Equal(t, []handlers.FieldError{
+		{Field: "swiftCodes[1].swiftCode", Code: handlers.FieldCodeDuplicate, Message: "SWIFT code already exists in the database"},
+	}, problem.Errors)
+}
+
+func TestAddSwiftCodesBatch_EmptyBatch(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/batch", map[string]interface{}{
+		"swiftCodes": []interface{}{},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, handlers.CodeValidationFailed, decodeProblem(t, recorder).Code)
+}
+
+func TestAddSwiftCodesBatch_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "NEWBUS33XXX").Return(nil, nil)
+	mockRepo.On("InsertSwiftCode", mock.Anything).Return(assert.AnError)
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes/batch", map[string]interface{}{
+		"swiftCodes": []interface{}{batchItem("NEWBUS33XXX", true)},
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, handlers.CodeInternalError, decodeProblem(t, recorder).Code)
+}
@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) handlers.Problem {
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem handlers.Problem
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestProblem_NotFoundCarriesRequestID(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKXX99ZZZ").Return(nil, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/BANKXX99ZZZ", nil)
+	req.Header.Set("X-Request-ID", "client-req-42")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "client-req-42", recorder.Header().Get("X-Request-ID"))
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, handlers.CodeSwiftNotFound, problem.Code)
+	assert.Equal(t, "/v1/swift-codes/BANKXX99ZZZ", problem.Instance)
+	assert.Equal(t, "client-req-42", problem.RequestID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProblem_GeneratedRequestID(t *testing.T) {
+	router := setupRouter(new(mocks.MockSwiftCodeRepository))
+
+	req, _ := http.NewRequest("GET", "/v2/unknown", nil)
+	req.Header.Set("X-Request-ID", "not a valid id!")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeRouteNotFound, problem.Code)
+	assert.Len(t, problem.RequestID, 32, "An invalid client ID should be replaced with a generated one")
+	assert.Equal(t, problem.RequestID, recorder.Header().Get("X-Request-ID"))
+}
+
+func TestProblem_ValidationListsEveryField(t *testing.T) {
+	router := setupRouter(new(mocks.MockSwiftCodeRepository))
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+		"swiftCode":     "BANK",
+		"bankName":      " ",
+		"countryISO2":   "U1",
+		"countryName":   "United States",
+		"isHeadquarter": false,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeValidationFailed, problem.Code)
+	assert.Equal(t, []handlers.FieldError{
+		{Field: "swiftCode", Code: handlers.FieldCodeInvalid, Message: "Invalid SWIFT code length. Must be 8 or 11 characters."},
+		{Field: "countryISO2", Code: handlers.FieldCodeInvalid, Message: "Invalid country ISO2 code. Must be exactly 2 uppercase letters."},
+		{Field: "bankName", Code: handlers.FieldCodeRequired, Message: "Bank name cannot be empty."},
+	}, problem.Errors)
+}
+
+func TestProblem_MissingRequiredFields(t *testing.T) {
+	router := setupRouter(new(mocks.MockSwiftCodeRepository))
+
+	recorder := sendJSON(router, "POST", "/v1/swift-codes", map[string]interface{}{
+		"swiftCode": "BANKUS33XXX",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeValidationFailed, problem.Code)
+
+	fields := make([]string, 0, len(problem.Errors))
+	for _, fieldError := range problem.Errors {
+		assert.Equal(t, handlers.FieldCodeRequired, fieldError.Code)
+		fields = append(fields, fieldError.Field)
+	}
+	assert.ElementsMatch(t, []string{"bankName", "countryISO2", "countryName", "isHeadquarter"}, fields)
+}
+
+func TestProblem_MalformedJSON(t *testing.T) {
+	router := setupRouter(new(mocks.MockSwiftCodeRepository))
+
+	req, _ := http.NewRequest("POST", "/v1/swift-codes", bytes.NewBufferString(`{"swiftCode": `))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, handlers.CodeInvalidRequest, problem.Code)
+	assert.Empty(t, problem.Errors)
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mroczekDNF/swift-api/internal/handlers"
+	"github.com/mroczekDNF/swift-api/internal/middleware"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+)
+
+// setupRouter registers the SWIFT code endpoints like routes.SetupRouter, backed by mockRepo.
+// The mutating middleware, e.g. handlers.Idempotency, runs before the handlers of the mutating routes.
+func setupRouter(mockRepo *mocks.MockSwiftCodeRepository, mutating ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID(), gin.CustomRecovery(handlers.Recovery))
+	router.NoRoute(handlers.NoRoute)
+
+	handler := handlers.NewSwiftCodeHandler(mockRepo)
+	mutate := func(h gin.HandlerFunc) []gin.HandlerFunc {
+		return append(append([]gin.HandlerFunc(nil), mutating...), h)
+	}
+
+	router.GET("/v1/swift-codes", handler.ListSwiftCodes)
+	router.GET("/v1/swift-codes/search", handler.SearchSwiftCodes)
+	router.GET("/v1/swift-codes/export", handler.ExportSwiftCodes)
+	router.GET("/v1/swift-codes/:swiftCode", handler.GetSwiftCodeDetails)
+	router.GET("/v1/swift-codes/country/:countryISO2", handler.GetSwiftCodesByCountry)
+	router.POST("/v1/swift-codes", mutate(handler.AddSwiftCode)...)
+	router.POST("/v1/swift-codes/batch", mutate(handler.AddSwiftCodesBatch)...)
+	router.POST("/v1/swift-codes/lookup", handler.LookupSwiftCodes)
+	router.PUT("/v1/swift-codes/:swiftCode", mutate(handler.UpdateSwiftCode)...)
+	router.PATCH("/v1/swift-codes/:swiftCode", mutate(handler.PatchSwiftCode)...)
+	router.DELETE("/v1/swift-codes/:swift-code", mutate(handler.DeleteSwiftCode)...)
+	router.GET("/v1/integrity/headquarter-links", handler.GetHeadquarterLinkIssues)
+	return router
+}
+
+func sendJSON(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	return sendIfMatch(router, method, path, "", body)
+}
+
+// sendIfMatch sends a JSON request with the given If-Match header; "*" matches any version.
+func sendIfMatch(router *gin.Engine, method, path, ifMatch string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchSwiftCodes_Success(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("SearchSwiftCodes", "agricole", 5).Return([]repositories.SwiftCodeSearchResult{
+		{
+			Record: models.SwiftCode{
+				SwiftCode:     "BANKFR55XXX",
+				BankName:      "Credit Agricole HQ",
+				Address:       "456 Paris Ave",
+				CountryISO2:   "FR",
+				CountryName:   "France",
+				IsHeadquarter: true,
+			},
+			Score: 1.06,
+		},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/search?q=%20agricole%20&limit=5", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "agricole", response["query"])
+	results := response["results"].([]interface{})
+	assert.Len(t, results, 1)
+	result := results[0].(map[string]interface{})
+	assert.Equal(t, "BANKFR55XXX", result["swiftCode"])
+	assert.Equal(t, "Credit Agricole HQ", result["bankName"])
+	assert.Equal(t, 1.06, result["score"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchSwiftCodes_InvalidParameters(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	for _, query := range []string{"", "q=a", "q=%20%20", "q=bank&limit=0", "q=bank&limit=abc"} {
+		req, _ := http.NewRequest("GET", "/v1/swift-codes/search?"+query, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+
+	mockRepo.AssertNotCalled(t, "SearchSwiftCodes", mock.Anything, mock.Anything)
+}
+
+func TestSearchSwiftCodes_RepositoryError(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("SearchSwiftCodes", "bank", 20).Return(nil, assert.AnError)
+
+	req, _ := http.NewRequest("GET", "/v1/swift-codes/search?q=bank", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,192 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateSwiftCode_Success(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	headquarterID := int64(1)
+	existing := &models.SwiftCode{
+		ID:            2,
+		SwiftCode:     "BANKUS33ABC",
+		BankName:      "Test Bank Branch",
+		Address:       "456 Tset Ave",
+		CountryISO2:   "US",
+		CountryName:   "UNITED STATES",
+		IsHeadquarter: false,
+		HeadquarterID: &headquarterID,
+		CodeType:      "BIC11",
+		TownName:      "NEW YORK",
+		TimeZone:      "America/New_York",
+	}
+	expected := &models.SwiftCode{
+		ID:            2,
+		SwiftCode:     "BANKUS33ABC",
+		BankName:      "Test Bank Branch",
+		Address:       "456 Test Ave",
+		CountryISO2:   "US",
+		CountryName:   "UNITED STATES",
+		IsHeadquarter: false,
+		HeadquarterID: &headquarterID,
+		CodeType:      "BIC11",
+		TownName:      "NEW YORK",
+		TimeZone:      "America/New_York",
+	}
+
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(existing, nil)
+	mockRepo.On("UpdateSwiftCode", expected).Return(nil)
+
+	recorder := sendIfMatch(router, "PUT", "/v1/swift-codes/bankus33abc", "*", map[string]interface{}{
+		"swiftCode":     "BANKUS33ABC",
+		"bankName":      "Test Bank Branch",
+		"address":       "456 Test Ave",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": false,
+		"townName":      "NEW YORK",
+		"timeZone":      "America/New_York",
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "SWIFT code updated successfully", response["message"])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSwiftCode_CannotChangeCode(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	existing := &models.SwiftCode{ID: 2, SwiftCode: "BANKUS33ABC", BankName: "Test Bank Branch", CountryISO2: "US", CountryName: "UNITED STATES"}
+	mockRepo.On("GetBySwiftCode", "BANKUS33ABC").Return(existing, nil)
+
+	recorder := sendIfMatch(router, "PUT", "/v1/swift-codes/BANKUS33ABC", "*", map[string]interface{}{
+		"swiftCode":     "BANKUS33DEF",
+		"bankName":      "Test Bank Branch",
+		"countryISO2":   "US",
+		"countryName":   "United States",
+		"isHeadquarter": false,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "SWIFT_CODE_IMMUTABLE", response["code"])
+	assert.Equal(t, "SWIFT code cannot be changed", response["detail"])
+
+	mockRepo.AssertNotCalled(t, "UpdateSwiftCode", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSwiftCode_NotFound(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	mockRepo.On("GetBySwiftCode", "BANKXX99ZZZ").Return(nil, nil)
+
+	recorder := sendIfMatch(router, "PUT", "/v1/swift-codes/BANKXX99ZZZ", "*", map[string]interface{}{
+		"swiftCode":     "BANKXX99ZZZ",
+		"bankName":      "Missing Bank",
+		"countryISO2":   "XX",
+		"countryName":   "Nowhere",
+		"isHeadquarter": false,
+	})
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSwiftCode_HeadquarterMustMatchCode(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	existing := &models.SwiftCode{ID: 1, SwiftCode: "BANKUS33XXX", BankName: "Test Bank HQ", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true}
+	mockRepo.On("GetBySwiftCode", "BANKUS33XXX").Return(existing, nil)
+
+	recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/BANKUS33XXX", "*", map[string]interface{}{
+		"isHeadquarter": false,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "VALIDATION_FAILED", response["code"])
+	fieldErrors := response["errors"].([]interface{})
+	assert.Equal(t, "isHeadquarter", fieldErrors[0].(map[string]interface{})["field"])
+
+	mockRepo.AssertNotCalled(t, "DetachBranchesFromHeadquarter", mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateSwiftCode", mock.Anything)
+}
+
+func TestPatchSwiftCode_MergeSemantics(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	existing := &models.SwiftCode{
+		ID:            7,
+		SwiftCode:     "BANKJP11XYZ",
+		BankName:      "Independent Branch JP",
+		Address:       "1 Tokyo Rd",
+		CountryISO2:   "JP",
+		CountryName:   "JAPAN",
+		IsHeadquarter: false,
+		CodeType:      "BIC11",
+	}
+	expected := *existing
+	expected.BankName = "Renamed Branch JP"
+	expected.Address = "UNKNOWN"
+
+	mockRepo.On("GetBySwiftCode", "BANKJP11XYZ").Return(existing, nil)
+	mockRepo.On("UpdateSwiftCode", &expected).Return(nil)
+
+	recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/BANKJP11XYZ", "*", map[string]interface{}{
+		"bankName": "  Renamed Branch JP ",
+		"address":  nil,
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchSwiftCode_RemovingRequiredFieldFails(t *testing.T) {
+	mockRepo := new(mocks.MockSwiftCodeRepository)
+	router := setupRouter(mockRepo)
+
+	existing := &models.SwiftCode{ID: 7, SwiftCode: "BANKJP11XYZ", BankName: "Independent Branch JP", CountryISO2: "JP", CountryName: "JAPAN"}
+	mockRepo.On("GetBySwiftCode", "BANKJP11XYZ").Return(existing, nil)
+
+	recorder := sendIfMatch(router, "PATCH", "/v1/swift-codes/BANKJP11XYZ", "*", map[string]interface{}{
+		"bankName": nil,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "VALIDATION_FAILED", response["code"])
+	fieldErrors := response["errors"].([]interface{})
+	assert.Len(t, fieldErrors, 1)
+	assert.Equal(t, "bankName", fieldErrors[0].(map[string]interface{})["field"])
+	assert.Equal(t, "Bank name cannot be empty.", fieldErrors[0].(map[string]interface{})["message"])
+
+	mockRepo.AssertNotCalled(t, "UpdateSwiftCode", mock.Anything)
+}
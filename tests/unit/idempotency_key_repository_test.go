@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mroczekDNF/swift-api/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	deleteExpiredKeyQuery = regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE key = $1")
+	reserveKeyQuery       = regexp.QuoteMeta("INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING;")
+	storedKeyQuery        = regexp.QuoteMeta("SELECT key, request_hash, status_code, content_type, etag, response_body, created_at FROM idempotency_keys WHERE key = $1;")
+)
+
+// TestReserveKey_Free - a free key is reserved and nil is returned
+func TestReserveKey_Free(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(deleteExpiredKeyQuery).WithArgs("retry-1", float64(86400), float64(600)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(reserveKeyQuery).WithArgs("retry-1", "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stored, err := repositories.NewIdempotencyKeyRepository(db).ReserveKey("retry-1", "hash", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestReserveKey_Stored - a taken key returns the stored response, or none while the request is in progress
+func TestReserveKey_Stored(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"key", "request_hash", "status_code", "content_type", "etag", "response_body", "created_at"}
+	createdAt := time.Now()
+
+	mock.ExpectExec(deleteExpiredKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(storedKeyQuery).WithArgs("retry-1").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("retry-1", "hash", 200, "application/json; charset=utf-8", `"7"`, []byte(`{"message":"SWIFT code added successfully"}`), createdAt))
+	mock.ExpectExec(deleteExpiredKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(storedKeyQuery).WithArgs("retry-2").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("retry-2", "hash", nil, nil, nil, nil, createdAt))
+
+	repo := repositories.NewIdempotencyKeyRepository(db)
+
+	stored, err := repo.ReserveKey("retry-1", "hash", time.Hour)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) && assert.NotNil(t, stored.StatusCode) {
+		assert.Equal(t, 200, *stored.StatusCode)
+		assert.Equal(t, "application/json; charset=utf-8", stored.ContentType)
+		assert.Equal(t, `"7"`, stored.ETag)
+		assert.JSONEq(t, `{"message":"SWIFT code added successfully"}`, string(stored.ResponseBody))
+	}
+
+	stored, err = repo.ReserveKey("retry-2", "hash", time.Hour)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Nil(t, stored.StatusCode, "A key without a response is still being processed")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestReserveKey_ReleasedConcurrently - a key released between the insert and the select is reserved again
+func TestReserveKey_ReleasedConcurrently(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(deleteExpiredKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(storedKeyQuery).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+	mock.ExpectExec(deleteExpiredKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stored, err := repositories.NewIdempotencyKeyRepository(db).ReserveKey("retry-1", "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeleteExpiredKeys - keys older than the window are removed
+func TestDeleteExpiredKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1);")).
+		WithArgs(float64(3600)).WillReturnResult(sqlmock.NewResult(0, 7))
+
+	removed, err := repositories.NewIdempotencyKeyRepository(db).DeleteExpiredKeys(time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mroczekDNF/swift-api/internal/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadMigrations - embedded migrations are ordered and have both directions
+func TestLoadMigrations(t *testing.T) {
+	all, err := migrations.Load()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(all), 3)
+
+	assert.Equal(t, int64(1), all[0].Version)
+	assert.Equal(t, "create_swift_codes", all[0].Name)
+	for i, migration := range all {
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+		if i > 0 {
+			assert.Greater(t, migration.Version, all[i-1].Version)
+		}
+	}
+}
+
+// expectMigrationLock expects the advisory lock and the tracking table setup
+func expectMigrationLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// TestMigratorUp_AppliesPending - only migrations missing from schema_migrations are applied
+func TestMigratorUp_AppliesPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	all, err := migrations.Load()
+	assert.NoError(t, err)
+
+	expectMigrationLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations;")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	for _, migration := range all[1:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(migration.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2);")).
+			WithArgs(migration.Version, migration.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(all)-1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigratorUp_FailureRollsBack - a failing migration is rolled back, not recorded and the lock is released
+func TestMigratorUp_FailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	all, err := migrations.Load()
+	assert.NoError(t, err)
+
+	expectMigrationLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations;")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(all[0].Up)).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigratorDown_RevertsLatest - only the most recently applied migration is reverted
+func TestMigratorDown_RevertsLatest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	all, err := migrations.Load()
+	assert.NoError(t, err)
+
+	expectMigrationLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations;")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(all[1].Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1;")).
+		WithArgs(all[1].Version).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	reverted, err := migrator.Down(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, reverted) {
+		assert.Equal(t, all[1].Version, reverted.Version)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigratorStatus - applied and pending migrations are reported
+func TestMigratorStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	appliedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expectMigrationLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations;")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	statuses, err := migrator.Status(context.Background())
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, statuses) {
+		assert.Equal(t, appliedAt, *statuses[0].AppliedAt)
+		assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
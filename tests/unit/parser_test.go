@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/mroczekDNF/swift-api/internal/services"
@@ -30,10 +31,10 @@ func TestParseSwiftCodes(t *testing.T) {
 	testFilePath := "test_swift_codes.csv"
 	testData := [][]string{
 		{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"},
-		{"PL", "ABCDEFSSXXX", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},
-		{"PL", "ABCDEFSS001", "BIC11", "Branch Bank", "Branch Street 2", "Krakow", "Poland", "Europe/Warsaw"},
-		{"US", "XYZXYZSS123", "BIC11", "US Branch", "5th Avenue", "Los Angeles", "USA", "America/New_York"},
-		{"US", "XYZXYZSSXXX", "BIC11", "US Bank HQ", "Wall Street", "New York", "USA", "America/New_York"},
+		{"PL", "ABCDPLSSXXX", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},
+		{"PL", "ABCDPLSS001", "BIC11", "Branch Bank", "Branch Street 2", "Krakow", "Poland", "Europe/Warsaw"},
+		{"US", "XYZXUSSS123", "BIC11", "US Branch", "5th Avenue", "Los Angeles", "United States of America", "America/New_York"},
+		{"US", "XYZXUSSSXXX", "BIC11", "US Bank HQ", "Wall Street", "New York", "United States", "America/New_York"},
 	}
 	err := createTestCSV(testFilePath, testData)
 	assert.NoError(t, err)
@@ -45,22 +46,24 @@ func TestParseSwiftCodes(t *testing.T) {
 	assert.Len(t, swiftCodes, 4)
 
 	// Checking each record in the order they are processed.
-	assert.Equal(t, "ABCDEFSSXXX", swiftCodes[0].SwiftCode)
+	// Headquarter links are resolved by the database after insert, so the parser leaves them empty.
+	assert.Equal(t, "ABCDPLSSXXX", swiftCodes[0].SwiftCode)
 	assert.True(t, swiftCodes[0].IsHeadquarter)
 	assert.Nil(t, swiftCodes[0].HeadquarterID)
+	assert.Equal(t, "BIC11", swiftCodes[0].CodeType)
+	assert.Equal(t, "Warsaw", swiftCodes[0].TownName)
+	assert.Equal(t, "Europe/Warsaw", swiftCodes[0].TimeZone)
+	assert.Equal(t, "POLAND", swiftCodes[0].CountryName)
 
-	assert.Equal(t, "ABCDEFSS001", swiftCodes[1].SwiftCode)
+	assert.Equal(t, "ABCDPLSS001", swiftCodes[1].SwiftCode)
 	assert.False(t, swiftCodes[1].IsHeadquarter)
-	assert.NotNil(t, swiftCodes[1].HeadquarterID)
-	// The HeadquarterID should equal the headquarters ID (the first record's ID plus 1).
-	assert.Equal(t, swiftCodes[0].ID+1, *swiftCodes[1].HeadquarterID)
+	assert.Nil(t, swiftCodes[1].HeadquarterID)
 
-	assert.Equal(t, "XYZXYZSS123", swiftCodes[2].SwiftCode)
+	assert.Equal(t, "XYZXUSSS123", swiftCodes[2].SwiftCode)
 	assert.False(t, swiftCodes[2].IsHeadquarter)
-	assert.NotNil(t, swiftCodes[2].HeadquarterID)
-	assert.Equal(t, swiftCodes[3].ID+1, *swiftCodes[2].HeadquarterID)
+	assert.Nil(t, swiftCodes[2].HeadquarterID)
 
-	assert.Equal(t, "XYZXYZSSXXX", swiftCodes[3].SwiftCode)
+	assert.Equal(t, "XYZXUSSSXXX", swiftCodes[3].SwiftCode)
 	assert.True(t, swiftCodes[3].IsHeadquarter)
 	assert.Nil(t, swiftCodes[3].HeadquarterID)
 }
@@ -101,9 +104,9 @@ func TestParseSwiftCodesEmptyFile(t *testing.T) {
 func TestParseSwiftCodesMixedData(t *testing.T) {
 	testData := [][]string{
 		{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"},
-		{"PL", "ABCDEFSSXXX", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},       // Valid.
-		{"", "ABCDEFSSXXX", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},         // Missing country code.
-		{"PL", "XYZXYZSS123", "BIC11", "Branch Bank", "Branch Street 2", "Krakow", "Poland", "Europe/Warsaw"}, // Valid.
+		{"PL", "ABCDPLSSXXX", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},       // Valid.
+		{"", "ABCDPLSSXXX", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},         // Missing country code.
+		{"PL", "XYZXPLSS123", "BIC11", "Branch Bank", "Branch Street 2", "Krakow", "Poland", "Europe/Warsaw"}, // Valid.
 		{"PL", "", "BIC11", "Bank HQ", "Main Street 1", "Warsaw", "Poland", "Europe/Warsaw"},                  // Missing SWIFT code.
 	}
 
@@ -116,8 +119,8 @@ func TestParseSwiftCodesMixedData(t *testing.T) {
 	assert.NoError(t, err)
 	// Only the two valid records should be processed.
 	assert.Len(t, swiftCodes, 2, "Expected two valid records")
-	assert.Equal(t, "ABCDEFSSXXX", swiftCodes[0].SwiftCode)
-	assert.Equal(t, "XYZXYZSS123", swiftCodes[1].SwiftCode)
+	assert.Equal(t, "ABCDPLSSXXX", swiftCodes[0].SwiftCode)
+	assert.Equal(t, "XYZXPLSS123", swiftCodes[1].SwiftCode)
 }
 
 // TestParseSwiftCodesInvalidFile tests that parsing a non-existent file returns an error.
@@ -139,7 +142,7 @@ func TestParseSwiftCodesInvalidSwiftFormat(t *testing.T) {
 		// Invalid SWIFT format: not matching expected pattern.
 		{"PL", "INVALID", "BIC11", "Invalid SWIFT", "Some Address", "City", "Poland", "Europe/Warsaw"},
 		// Valid SWIFT format but with lowercase letters; after normalization it should be accepted.
-		{"PL", "abcdEFSSXXX", "BIC11", "Valid SWIFT", "Some Address", "City", "Poland", "Europe/Warsaw"},
+		{"PL", "abcdPLSSXXX", "BIC11", "Valid SWIFT", "Some Address", "City", "Poland", "Europe/Warsaw"},
 	}
 	testFilePath := "invalid_swift_format.csv"
 	err := createTestCSV(testFilePath, testData)
@@ -151,7 +154,7 @@ func TestParseSwiftCodesInvalidSwiftFormat(t *testing.T) {
 	// Only the record with valid (normalized) SWIFT code should be processed.
 	assert.Len(t, swiftCodes, 1, "Only records with valid normalized SWIFT codes should be processed")
 	// Check that the SWIFT code is normalized to uppercase.
-	assert.Equal(t, "ABCDEFSSXXX", swiftCodes[0].SwiftCode)
+	assert.Equal(t, "ABCDPLSSXXX", swiftCodes[0].SwiftCode)
 }
 
 // TestParseSwiftCodesInvalidCountryISO2 tests that records with invalid country ISO2 codes are rejected.
@@ -159,9 +162,9 @@ func TestParseSwiftCodesInvalidCountryISO2(t *testing.T) {
 	testData := [][]string{
 		{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"},
 		// Invalid country code: only one letter.
-		{"P", "ABCDEFSSXXX", "BIC11", "Bank HQ", "Some Address", "City", "Poland", "Europe/Warsaw"},
+		{"P", "ABCDPLSSXXX", "BIC11", "Bank HQ", "Some Address", "City", "Poland", "Europe/Warsaw"},
 		// Invalid country code: three characters.
-		{"USA", "XYZXYZSS123", "BIC11", "Branch", "Some Address", "City", "USA", "America/New_York"},
+		{"USA", "XYZXUSSS123", "BIC11", "Branch", "Some Address", "City", "USA", "America/New_York"},
 	}
 	testFilePath := "invalid_country_iso2.csv"
 	err := createTestCSV(testFilePath, testData)
@@ -179,9 +182,9 @@ func TestParseSwiftCodesMissingFields(t *testing.T) {
 	testData := [][]string{
 		{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"},
 		// Missing NAME field (bank name is empty).
-		{"PL", "ABCDEFSSXXX", "BIC11", "", "Some Address", "City", "Poland", "Europe/Warsaw"},
+		{"PL", "ABCDPLSSXXX", "BIC11", "", "Some Address", "City", "Poland", "Europe/Warsaw"},
 		// Missing CODE TYPE field.
-		{"PL", "XYZXYZSS123", "", "Bank", "Some Address", "City", "Poland", "Europe/Warsaw"},
+		{"PL", "XYZXUSSS123", "", "Bank", "Some Address", "City", "Poland", "Europe/Warsaw"},
 	}
 	testFilePath := "missing_fields.csv"
 	err := createTestCSV(testFilePath, testData)
@@ -199,7 +202,7 @@ func TestParseSwiftCodesNormalization(t *testing.T) {
 	testData := [][]string{
 		{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"},
 		// SWIFT code in mixed case should be normalized.
-		{"PL", "aBcDeFssXxX", "BIC11", "Bank HQ", "Some Address", "City", "Poland", "Europe/Warsaw"},
+		{"PL", "aBcDpLssXxX", "BIC11", "Bank HQ", "Some Address", "City", "Poland", "Europe/Warsaw"},
 	}
 	testFilePath := "normalization_test.csv"
 	err := createTestCSV(testFilePath, testData)
@@ -210,5 +213,168 @@ func TestParseSwiftCodesNormalization(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, swiftCodes, 1)
 	// Check that the SWIFT code is normalized to uppercase.
-	assert.Equal(t, "ABCDEFSSXXX", swiftCodes[0].SwiftCode)
+	assert.Equal(t, "ABCDPLSSXXX", swiftCodes[0].SwiftCode)
+}
+
+// TestParseSwiftCodesCountryConsistency tests that the SWIFT code, country code and country name must agree.
+func TestParseSwiftCodesCountryConsistency(t *testing.T) {
+	testData := [][]string{
+		{"COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE"},
+		// SWIFT code country segment (US) differs from the country code.
+		{"PL", "BANKUS33XXX", "BIC11", "Bank HQ", "Some Address", "City", "Poland", "Europe/Warsaw"},
+		// Not an ISO 3166 country.
+		{"QQ", "BANKQQ33XXX", "BIC11", "Bank HQ", "Some Address", "City", "Narnia", "Europe/Warsaw"},
+		// Country name belongs to a different country.
+		{"PL", "BANKPL33XXX", "BIC11", "Bank HQ", "Some Address", "City", "Narnia", "Europe/Warsaw"},
+		// Valid: the name is matched case-insensitively and stored in its canonical form.
+		{"GB", "BANKGB22XXX", "BIC11", "Bank HQ", "Some Address", "London", "united  kingdom", "Europe/London"},
+	}
+	testFilePath := "country_consistency.csv"
+	err := createTestCSV(testFilePath, testData)
+	assert.NoError(t, err)
+	defer os.Remove(testFilePath)
+
+	swiftCodes, err := services.ParseSwiftCodes(testFilePath)
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 1)
+	assert.Equal(t, "BANKGB22XXX", swiftCodes[0].SwiftCode)
+	assert.Equal(t, "UNITED KINGDOM", swiftCodes[0].CountryName)
+}
+
+// TestParseSwiftCodesFromReportsRejects tests that rejected rows are reported with their line and reason.
+func TestParseSwiftCodesFromReportsRejects(t *testing.T) {
+	input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,ABCDPLSSXXX,BIC11,Bank HQ,\"Main Street 1\nWarsaw\",Warsaw,Poland,Europe/Warsaw\n" +
+		"PL,ABCDDESSXXX,BIC11,Bank HQ,Main Street 1,Warsaw,Poland,Europe/Warsaw\n" +
+		"PL,ABCDPLSS001,BIC11,,Main Street 1,Warsaw,Poland,Europe/Warsaw\n" +
+		"PL,abcdplssxxx,BIC11,Bank HQ,Main Street 1,Warsaw,Poland,Europe/Warsaw\n"
+
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(strings.NewReader(input), services.DefaultColumnMapping, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 1, "A repeated SWIFT code is returned once")
+
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Accepted)
+	assert.Len(t, report.Rejected, 2)
+	assert.Equal(t, 4, report.Rejected[0].Line, "Line numbers should account for quoted line breaks")
+	assert.Equal(t, "ABCDDESSXXX", report.Rejected[0].SwiftCode)
+	assert.Equal(t, services.ReasonCountryMismatch, report.Rejected[0].ReasonCode)
+	assert.Equal(t, 5, report.Rejected[1].Line)
+	assert.Equal(t, services.ReasonMissingBankName, report.Rejected[1].ReasonCode)
+	// The address of the first occurrence spans two lines, so the records conflict.
+	assert.Equal(t, []services.DuplicateRecord{{Line: 6, FirstLine: 2, SwiftCode: "ABCDPLSSXXX", Kind: services.DuplicateConflicting}}, report.Duplicates)
+
+	assert.Equal(t, "4 records: 2 accepted, 2 rejected (COUNTRY_MISMATCH: 1, MISSING_BANK_NAME: 1), 1 duplicates (1 conflicting)", report.Summary())
+}
+
+// TestParseReportWriteRejects tests that rejected rows are written with their line, reason and original fields.
+func TestParseReportWriteRejects(t *testing.T) {
+	report := services.ParseReport{
+		Rejected: []services.RejectedRecord{
+			{Line: 7, SwiftCode: "BANK", ReasonCode: services.ReasonInvalidSwiftCode, Reason: "too short", Fields: []string{"PL", "BANK", "BIC11"}},
+		},
+	}
+
+	var buffer strings.Builder
+	assert.NoError(t, report.WriteRejects(&buffer))
+	assert.Equal(t, "LINE,REASON CODE,REASON\n7,INVALID_SWIFT_CODE,too short,PL,BANK,BIC11\n", buffer.String())
+}
+
+// TestParseSwiftCodesVendorLayout tests that columns are located by header name, whatever their order.
+func TestParseSwiftCodesVendorLayout(t *testing.T) {
+	input := "bic,Bank Name,City,Country,ISO2,Type,Rating\n" +
+		"ABCDPLSSXXX,Bank HQ,Warsaw,Poland,PL,BIC11,A+\n"
+
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(strings.NewReader(input), services.DefaultColumnMapping, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Rejected)
+	assert.Len(t, swiftCodes, 1)
+	assert.Equal(t, "ABCDPLSSXXX", swiftCodes[0].SwiftCode)
+	assert.Equal(t, "Bank HQ", swiftCodes[0].BankName)
+	assert.Equal(t, "Warsaw", swiftCodes[0].TownName)
+	assert.Equal(t, "UNKNOWN", swiftCodes[0].Address, "A missing optional column leaves the field empty")
+	assert.Equal(t, "", swiftCodes[0].TimeZone)
+}
+
+// TestParseSwiftCodesMissingColumns tests that a header without a required column is rejected as a whole.
+func TestParseSwiftCodesMissingColumns(t *testing.T) {
+	input := "COUNTRY ISO2 CODE,BANK IDENTIFIER,CODE TYPE,NAME,COUNTRY NAME\n" +
+		"PL,ABCDPLSSXXX,BIC11,Bank HQ,Poland\n"
+
+	_, _, err := services.ParseSwiftCodesFrom(strings.NewReader(input), services.DefaultColumnMapping, services.DuplicateFirstWins)
+
+	var missingErr *services.MissingColumnsError
+	assert.True(t, errors.As(err, &missingErr), "Expected MissingColumnsError, got: %v", err)
+	assert.Equal(t, []services.Field{services.FieldSwiftCode}, missingErr.Missing)
+	assert.Contains(t, err.Error(), "swiftCode (expected one of: SWIFT CODE")
+}
+
+// TestParseSwiftCodesCustomMapping tests that a mapping config adds header names without code changes.
+func TestParseSwiftCodesCustomMapping(t *testing.T) {
+	mapping, err := services.ParseColumnMapping([]byte(`{"swiftCode": ["Bank Identifier"]}`))
+	assert.NoError(t, err)
+
+	input := "COUNTRY ISO2 CODE,BANK IDENTIFIER,CODE TYPE,NAME,COUNTRY NAME\n" +
+		"PL,ABCDPLSSXXX,BIC11,Bank HQ,Poland\n"
+
+	swiftCodes, _, err := services.ParseSwiftCodesFrom(strings.NewReader(input), mapping, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 1)
+
+	_, err = services.ParseColumnMapping([]byte(`{"rating": ["RATING"]}`))
+	assert.Error(t, err, "Unknown fields should be rejected")
+}
+
+// TestParseColumnMappingAliasForTwoFields tests that a header name may only name one field.
+func TestParseColumnMappingAliasForTwoFields(t *testing.T) {
+	_, err := services.ParseColumnMapping([]byte(`{"swiftCode": ["Identifier"], "bankName": ["IDENTIFIER"]}`))
+	assert.EqualError(t, err, `invalid column mapping: header "IDENTIFIER" is assigned to both swiftCode and bankName`)
+
+	_, err = services.ParseColumnMapping([]byte(`{"bankName": ["Swift Code"]}`))
+	assert.EqualError(t, err, `invalid column mapping: header "SWIFT CODE" is assigned to both swiftCode and bankName`,
+		"An alias may not take over a default alias of another field")
+
+	_, err = services.ParseColumnMapping([]byte(`{"swiftCode": ["swift code"]}`))
+	assert.NoError(t, err, "Repeating an alias of the same field is harmless")
+}
+
+// TestParseSwiftCodesAmbiguousColumns tests that two columns mapping to the same field are reported.
+func TestParseSwiftCodesAmbiguousColumns(t *testing.T) {
+	input := "COUNTRY ISO2 CODE,SWIFT CODE,BIC,CODE TYPE,NAME,COUNTRY NAME\n"
+
+	_, _, err := services.ParseSwiftCodesFrom(strings.NewReader(input), services.DefaultColumnMapping, services.DuplicateFirstWins)
+	assert.EqualError(t, err, "columns 2 and 3 both map to swiftCode")
+}
+
+// TestParseSwiftCodesMisSizedRows tests that a row with too few fields is rejected on its own and extra fields are ignored.
+func TestParseSwiftCodesMisSizedRows(t *testing.T) {
+	input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,ABCDPLSSXXX,BIC11,Bank HQ,Main Street 1,Warsaw,Poland\n" +
+		"PL,ABCDPLSS001,BIC11,Bank Branch,Main Street 2,Warsaw,Poland,Europe/Warsaw,extra\n" +
+		"PL,ABCDPLSS002,BIC11,Bank Branch,Main Street 3,Warsaw,Poland,Europe/Warsaw\n"
+
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(strings.NewReader(input), nil, services.DuplicateFirstWins)
+	assert.NoError(t, err, "A nil mapping should mean DefaultColumnMapping")
+	assert.Len(t, swiftCodes, 2)
+	assert.Equal(t, 3, report.Total)
+	assert.Len(t, report.Rejected, 1)
+	assert.Equal(t, 2, report.Rejected[0].Line)
+	assert.Equal(t, "ABCDPLSSXXX", report.Rejected[0].SwiftCode)
+	assert.Equal(t, services.ReasonInsufficientData, report.Rejected[0].ReasonCode)
+	assert.Equal(t, "insufficient data: 7 of 8 fields", report.Rejected[0].Reason)
+}
+
+// TestParseSwiftCodesBIC8 tests that an 8-character code is stored in its 11-character form and repeats its BIC11.
+func TestParseSwiftCodesBIC8(t *testing.T) {
+	input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,ABCDPLSS,BIC8,Bank HQ,Main Street 1,Warsaw,Poland,Europe/Warsaw\n" +
+		"PL,ABCDPLSSXXX,BIC8,Bank HQ,Main Street 1,Warsaw,Poland,Europe/Warsaw\n"
+
+	swiftCodes, report, err := services.ParseSwiftCodesFrom(strings.NewReader(input), nil, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 1)
+	assert.Equal(t, "ABCDPLSSXXX", swiftCodes[0].SwiftCode)
+	assert.True(t, swiftCodes[0].IsHeadquarter)
+	assert.Equal(t, "BIC8", swiftCodes[0].CodeType)
+	assert.Len(t, report.Duplicates, 1, "A BIC8 and its BIC11 are the same record")
 }
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/services"
+)
+
+// benchmarkRows is the size of the generated directory file; raise it to see the
+// in-memory parser grow while the pipeline stays flat.
+const benchmarkRows = 200000
+
+// measurePeakHeap samples the live heap while fn runs and reports the peak as a benchmark metric.
+func measurePeakHeap(b *testing.B, fn func()) {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+
+	var peak uint64
+	done := make(chan struct{})
+	var sampler sync.WaitGroup
+	sampler.Add(1)
+	go func() {
+		defer sampler.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			peak = max(peak, stats.HeapAlloc)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	fn()
+	close(done)
+	sampler.Wait()
+
+	if peak > baseline {
+		b.ReportMetric(float64(peak-baseline)/(1<<20), "peak-heap-MB")
+	}
+}
+
+// BenchmarkParseSwiftCodesFrom - the in-memory parser, reading the whole file before returning it
+func BenchmarkParseSwiftCodesFrom(b *testing.B) {
+	b.ReportAllocs()
+	measurePeakHeap(b, func() {
+		for i := 0; i < b.N; i++ {
+			swiftCodes, _, err := services.ParseSwiftCodesFrom(newDirectoryReader(benchmarkRows), services.DefaultColumnMapping, services.DuplicateFirstWins)
+			if err != nil {
+				b.Fatalf("Error parsing SWIFT codes: %v", err)
+			}
+			runtime.KeepAlive(swiftCodes)
+		}
+	})
+	b.ReportMetric(float64(benchmarkRows*b.N)/b.Elapsed().Seconds(), "rows/s")
+}
+
+// BenchmarkStreamSwiftCodes - the streaming pipeline, discarding each batch once handed over
+func BenchmarkStreamSwiftCodes(b *testing.B) {
+	b.ReportAllocs()
+	measurePeakHeap(b, func() {
+		for i := 0; i < b.N; i++ {
+			_, err := services.StreamSwiftCodes(context.Background(), newDirectoryReader(benchmarkRows), services.DefaultColumnMapping, services.DuplicateFirstWins,
+				services.StreamOptions{}, func([]models.SwiftCode) error { return nil })
+			if err != nil {
+				b.Fatalf("Error streaming SWIFT codes: %v", err)
+			}
+		}
+	})
+	b.ReportMetric(float64(benchmarkRows*b.N)/b.Elapsed().Seconds(), "rows/s")
+}
@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// directoryReader generates a SWIFT directory file of the given number of rows on the fly,
+// so that large inputs do not have to be held in memory. Rows 25, 75, 125, ... have an
+// invalid country code and every 100th row repeats the previous SWIFT code.
+type directoryReader struct {
+	rows    int
+	row     int
+	pending []byte
+}
+
+func newDirectoryReader(rows int) *directoryReader {
+	return &directoryReader{rows: rows, pending: []byte("COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n")}
+}
+
+func (r *directoryReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.row == r.rows {
+			return 0, io.EOF
+		}
+		r.row++
+		code, country := r.row, "PL"
+		if r.row%100 == 0 {
+			code--
+		}
+		if r.row%50 == 25 {
+			country = "XX"
+		}
+		bank := code / 100000
+		r.pending = fmt.Appendf(r.pending, "%s,BN%c%cPL%02d%03d,BIC11,Bank %d,Street %d,Warsaw,POLAND,Europe/Warsaw\n",
+			country, 'A'+bank/26%26, 'A'+bank%26, code/1000%100, code%1000, r.row, r.row)
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// TestStreamSwiftCodes_MatchesParser - the pipeline yields the records and report of ParseSwiftCodesFrom, in file order
+func TestStreamSwiftCodes_MatchesParser(t *testing.T) {
+	expectedCodes, expectedReport, err := services.ParseSwiftCodesFrom(newDirectoryReader(5000), services.DefaultColumnMapping, services.DuplicateFirstWins)
+	assert.NoError(t, err)
+
+	var swiftCodes []models.SwiftCode
+	opts := services.StreamOptions{Workers: 4, Buffer: 16, BatchSize: 64}
+	report, err := services.StreamSwiftCodes(context.Background(), newDirectoryReader(5000), services.DefaultColumnMapping, services.DuplicateFirstWins, opts,
+		func(batch []models.SwiftCode) error {
+			assert.LessOrEqual(t, len(batch), 64)
+			swiftCodes = append(swiftCodes, batch...)
+			return nil
+		})
+	assert.NoError(t, err)
+
+	assert.Equal(t, expectedCodes, swiftCodes)
+	assert.Equal(t, expectedReport, report)
+	assert.Equal(t, 5000, report.Total)
+	assert.Len(t, report.Rejected, 100)
+	assert.Len(t, report.Duplicates, 50)
+}
+
+// TestStreamSwiftCodes_SinkErrorStops - an error from the sink stops reading and is returned
+func TestStreamSwiftCodes_SinkErrorStops(t *testing.T) {
+	input := newDirectoryReader(100000)
+	opts := services.StreamOptions{Workers: 2, Buffer: 8, BatchSize: 10}
+
+	report, err := services.StreamSwiftCodes(context.Background(), input, services.DefaultColumnMapping, services.DuplicateFirstWins, opts,
+		func(batch []models.SwiftCode) error {
+			return errors.New("disk full")
+		})
+	assert.EqualError(t, err, "disk full")
+	assert.Less(t, input.row, 1000, "Reading should stop shortly after the sink fails")
+	assert.Less(t, report.Total, 1000)
+}
+
+// TestStreamSwiftCodes_InvalidInput - header and CSV errors are returned
+func TestStreamSwiftCodes_InvalidInput(t *testing.T) {
+	sink := func([]models.SwiftCode) error { return nil }
+
+	_, err := services.StreamSwiftCodes(context.Background(), strings.NewReader("COUNTRY ISO2 CODE,NAME\n"), services.DefaultColumnMapping, services.DuplicateFirstWins, services.StreamOptions{}, sink)
+	var missingErr *services.MissingColumnsError
+	assert.True(t, errors.As(err, &missingErr), "Expected MissingColumnsError, got: %v", err)
+
+	input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,ABCDPLSSXXX,BIC11,Bank HQ,\"Main Street,Warsaw,POLAND,Europe/Warsaw\n"
+	_, err = services.StreamSwiftCodes(context.Background(), strings.NewReader(input), services.DefaultColumnMapping, services.DuplicateFirstWins, services.StreamOptions{}, sink)
+	assert.Error(t, err)
+}
+
+// TestStreamSwiftCodes_MisSizedRows - a short row is rejected without stopping the pipeline
+func TestStreamSwiftCodes_MisSizedRows(t *testing.T) {
+	input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+		"PL,ABCDPLSSXXX,BIC11,Bank HQ\n" +
+		"PL,ABCDPLSS001,BIC11,Bank Branch,Main Street 2,Warsaw,Poland,Europe/Warsaw\n"
+
+	var swiftCodes []models.SwiftCode
+	report, err := services.StreamSwiftCodes(context.Background(), strings.NewReader(input), nil, services.DuplicateFirstWins, services.StreamOptions{},
+		func(batch []models.SwiftCode) error {
+			swiftCodes = append(swiftCodes, batch...)
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 1)
+	assert.Len(t, report.Rejected, 1)
+	assert.Equal(t, services.ReasonInsufficientData, report.Rejected[0].ReasonCode)
+}
+
+// TestLoadSwiftCodesFromReader_Batches - without pgx the streamed records are inserted in batches inside one transaction
+func TestLoadSwiftCodesFromReader_Batches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	insert := regexp.QuoteMeta("INSERT INTO swift_codes (swift_code, bank_name, address, country_iso2, country_name, is_headquarter, code_type, town_name, time_zone) VALUES")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1000))
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 470))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = hq.id")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes AS branch SET headquarter_id = NULL")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	report, err := services.LoadSwiftCodesFromReader(db, newDirectoryReader(1500), services.DefaultColumnMapping, services.DuplicateFirstWins, services.StreamOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1470, report.Accepted)
+	assert.Len(t, report.Rejected, 30)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestLoadSwiftCodesFromReader_RollbackOnFailure - a failing batch rolls back the whole load
+func TestLoadSwiftCodesFromReader_RollbackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	insert := regexp.QuoteMeta("INSERT INTO swift_codes")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1000))
+	mock.ExpectExec(insert).WillReturnError(errors.New("duplicate key value"))
+	mock.ExpectRollback()
+
+	_, err = services.LoadSwiftCodesFromReader(db, newDirectoryReader(5000), services.DefaultColumnMapping, services.DuplicateFirstWins, services.StreamOptions{})
+	assert.EqualError(t, err, "duplicate key value")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
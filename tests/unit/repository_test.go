@@ -1,10 +1,15 @@
 package unit
 
 import (
+	"context"
+	"database/sql/driver"
+	"errors"
 	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mroczekDNF/swift-api/internal/models"
 	"github.com/mroczekDNF/swift-api/internal/repositories"
 	"github.com/stretchr/testify/assert"
 )
@@ -21,18 +26,21 @@ func TestGetBySwiftCode_WithAddress(t *testing.T) {
 	code := "ABC123XXX"
 
 	query := regexp.QuoteMeta(`
-		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id
+		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone, version
 		FROM swift_codes WHERE swift_code = $1;`)
 
 	rows := sqlmock.NewRows([]string{
-		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id",
-	}).AddRow(1, code, "Bank A", "123 Bank Street", "US", "United States", true, nil)
+		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id", "code_type", "town_name", "time_zone", "version",
+	}).AddRow(1, code, "Bank A", "123 Bank Street", "US", "United States", true, nil, "BIC11", "NEW YORK", "America/New_York", 1)
 
 	mock.ExpectQuery(query).WithArgs(code).WillReturnRows(rows)
 
 	swift, err := repo.GetBySwiftCode(code)
 	assert.NoError(t, err)
 	assert.Equal(t, "123 Bank Street", swift.Address)
+	assert.Equal(t, "BIC11", swift.CodeType)
+	assert.Equal(t, "NEW YORK", swift.TownName)
+	assert.Equal(t, "America/New_York", swift.TimeZone)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -46,18 +54,20 @@ func TestGetBySwiftCode_NoAddress(t *testing.T) {
 	code := "ABC123XXX"
 
 	query := regexp.QuoteMeta(`
-		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id
+		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone, version
 		FROM swift_codes WHERE swift_code = $1;`)
 
 	rows := sqlmock.NewRows([]string{
-		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id",
-	}).AddRow(1, code, "Bank A", nil, "US", "United States", true, nil)
+		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id", "code_type", "town_name", "time_zone", "version",
+	}).AddRow(1, code, "Bank A", nil, "US", "United States", true, nil, nil, nil, nil, 1)
 
 	mock.ExpectQuery(query).WithArgs(code).WillReturnRows(rows)
 
 	swift, err := repo.GetBySwiftCode(code)
 	assert.NoError(t, err)
 	assert.Equal(t, "UNKNOWN", swift.Address)
+	assert.Empty(t, swift.TownName)
+	assert.Empty(t, swift.TimeZone)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -71,14 +81,14 @@ func TestGetByCountryISO2(t *testing.T) {
 	countryISO2 := "US"
 
 	query := regexp.QuoteMeta(`
-		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id
+		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone, version
 		FROM swift_codes WHERE country_iso2 = $1;`)
 
 	rows := sqlmock.NewRows([]string{
-		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id",
+		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id", "code_type", "town_name", "time_zone", "version",
 	}).
-		AddRow(1, "ABC123XXX", "Bank A", "123 Bank Street", "US", "United States", true, nil).
-		AddRow(2, "DEF456", "Bank B", nil, "US", "United States", false, 1)
+		AddRow(1, "ABC123XXX", "Bank A", "123 Bank Street", "US", "United States", true, nil, "BIC11", "TOWN", "UTC", 1).
+		AddRow(2, "DEF456", "Bank B", nil, "US", "United States", false, 1, "BIC11", "TOWN", "UTC", 1)
 
 	mock.ExpectQuery(query).WithArgs(countryISO2).WillReturnRows(rows)
 
@@ -106,3 +116,299 @@ func TestDetachBranchesFromHeadquarter(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestWithTx_Commit - all steps succeed and the transaction is committed
+func TestWithTx_Commit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes SET headquarter_id = NULL WHERE headquarter_id = $1;")).
+		WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM swift_codes WHERE swift_code = $1 AND version = $2;")).
+		WithArgs("BANKUS33XXX", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.WithTx(context.Background(), func(tx repositories.SwiftCodeRepositoryInterface) error {
+		if err := tx.DetachBranchesFromHeadquarter(1); err != nil {
+			return err
+		}
+		return tx.DeleteSwiftCode("BANKUS33XXX", 1)
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestWithTx_RollbackOnMidStepFailure - a failing second step rolls back the first one
+func TestWithTx_RollbackOnMidStepFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE swift_codes SET headquarter_id = NULL WHERE headquarter_id = $1;")).
+		WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM swift_codes WHERE swift_code = $1 AND version = $2;")).
+		WithArgs("BANKUS33XXX", int64(1)).WillReturnError(errors.New("connection lost"))
+	mock.ExpectRollback()
+
+	err = repo.WithTx(context.Background(), func(tx repositories.SwiftCodeRepositoryInterface) error {
+		if err := tx.DetachBranchesFromHeadquarter(1); err != nil {
+			return err
+		}
+		return tx.DeleteSwiftCode("BANKUS33XXX", 1)
+	})
+	assert.EqualError(t, err, "connection lost")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestWithTx_Nested - a nested unit of work joins the outer transaction
+func TestWithTx_Nested(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM swift_codes WHERE swift_code = $1 AND version = $2;")).
+		WithArgs("BANKUS33ABC", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	err = repo.WithTx(context.Background(), func(tx repositories.SwiftCodeRepositoryInterface) error {
+		if err := tx.WithTx(context.Background(), func(inner repositories.SwiftCodeRepositoryInterface) error {
+			return inner.DeleteSwiftCode("BANKUS33ABC", 1)
+		}); err != nil {
+			return err
+		}
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindHeadquarterLinkIssues - each kind of broken link is classified
+func TestFindHeadquarterLinkIssues(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	rows := sqlmock.NewRows([]string{"swift_code", "headquarter_id", "swift_code", "is_headquarter", "swift_code"}).
+		AddRow("BANKUS33ABC", nil, nil, nil, "BANKUS33XXX").
+		AddRow("BANKUS33DEF", 99, nil, nil, "BANKUS33XXX").
+		AddRow("BANKCA77AAA", 4, "BANKCA66XXX", true, nil).
+		AddRow("BANKIN77AAA", 5, "BANKIN77BBB", false, "BANKIN77XXX")
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swift_codes AS branch")).WillReturnRows(rows)
+
+	issues, err := repo.FindHeadquarterLinkIssues()
+	assert.NoError(t, err)
+	assert.Len(t, issues, 4)
+	assert.Equal(t, "MISSING_LINK", issues[0].Problem)
+	assert.Equal(t, "DANGLING_LINK", issues[1].Problem)
+	assert.Equal(t, "WRONG_INSTITUTION", issues[2].Problem)
+	assert.Equal(t, "BANKCA66XXX", *issues[2].LinkedSwiftCode)
+	assert.Nil(t, issues[2].ExpectedHeadquarter)
+	assert.Equal(t, "NOT_A_HEADQUARTER", issues[3].Problem)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestListSwiftCodes - filters, keyset condition and ordering are translated to SQL
+func TestListSwiftCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+	isHeadquarter := true
+
+	query := regexp.QuoteMeta(`SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone, version FROM swift_codes
+		WHERE country_iso2 = $1 AND bank_name ILIKE $2 AND is_headquarter = $3 AND (bank_name, swift_code) > ($4, $5)
+		ORDER BY bank_name ASC, swift_code ASC LIMIT $6;`)
+
+	rows := sqlmock.NewRows([]string{
+		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id", "code_type", "town_name", "time_zone", "version",
+	}).AddRow(3, "BANKUS44XXX", "Test Bank", "1 Road", "US", "United States", true, nil, "BIC11", "TOWN", "UTC", 1)
+
+	mock.ExpectQuery(query).WithArgs("US", `%100\%%`, true, "Bank A", "BANKUS33XXX", 11).WillReturnRows(rows)
+
+	swiftCodes, err := repo.ListSwiftCodes(repositories.SwiftCodeFilter{
+		CountryISO2:   "US",
+		BankName:      "100%",
+		IsHeadquarter: &isHeadquarter,
+		SortBy:        "bankName",
+		After:         &repositories.SwiftCodeCursor{SortValue: "Bank A", SwiftCode: "BANKUS33XXX"},
+		Limit:         11,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 1)
+	assert.Equal(t, "BANKUS44XXX", swiftCodes[0].SwiftCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSearchSwiftCodes - results are returned with their relevance score
+func TestSearchSwiftCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	rows := sqlmock.NewRows([]string{
+		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id", "code_type", "town_name", "time_zone", "version", "score",
+	}).
+		AddRow(5, "BANKFR55XXX", "Credit Agricole HQ", "456 Paris Ave", "FR", "France", true, nil, "BIC11", "TOWN", "UTC", 1, 1.06).
+		AddRow(9, "AGRIPLPRXXX", "Bank Agri", nil, "PL", "POLAND", true, nil, "BIC11", "TOWN", "UTC", 1, 0.4)
+
+	mock.ExpectQuery(regexp.QuoteMeta("plainto_tsquery('simple', $1)")).WithArgs("agricole", 20).WillReturnRows(rows)
+
+	results, err := repo.SearchSwiftCodes("agricole", 20)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "BANKFR55XXX", results[0].Record.SwiftCode)
+	assert.Equal(t, 1.06, results[0].Score)
+	assert.Equal(t, "UNKNOWN", results[1].Record.Address)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// arrayArgConverter lets sqlmock accept the []string arguments the pgx driver binds as arrays.
+type arrayArgConverter struct{}
+
+func (arrayArgConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if codes, ok := v.([]string); ok {
+		return codes, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// TestGetBySwiftCodes - several codes are fetched with a single array query
+func TestGetBySwiftCodes(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(arrayArgConverter{}))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+	codes := []string{"BANKUS33XXX", "BANKUS33ABC", "BANKDE44XXX"}
+
+	query := regexp.QuoteMeta(`
+		SELECT id, swift_code, bank_name, address, country_iso2, country_name, is_headquarter, headquarter_id, code_type, town_name, time_zone, version
+		FROM swift_codes WHERE swift_code = ANY($1);`)
+
+	rows := sqlmock.NewRows([]string{
+		"id", "swift_code", "bank_name", "address", "country_iso2", "country_name", "is_headquarter", "headquarter_id", "code_type", "town_name", "time_zone", "version",
+	}).
+		AddRow(1, "BANKUS33XXX", "Bank A", "123 Bank Street", "US", "UNITED STATES", true, nil, "BIC11", "NEW YORK", nil, 1).
+		AddRow(2, "BANKUS33ABC", "Bank A", nil, "US", "UNITED STATES", false, 1, "BIC11", "NEW YORK", nil, 1)
+
+	mock.ExpectQuery(query).WithArgs(codes).WillReturnRows(rows)
+
+	swiftCodes, err := repo.GetBySwiftCodes(codes)
+	assert.NoError(t, err)
+	assert.Len(t, swiftCodes, 2)
+	assert.Equal(t, "BANKUS33ABC", swiftCodes[1].SwiftCode)
+	assert.Equal(t, "UNKNOWN", swiftCodes[1].Address)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestInsertSwiftCode_UniqueViolation - a unique violation is reported as ErrDuplicateSwiftCode
+func TestInsertSwiftCode_UniqueViolation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO swift_codes")).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "swift_codes_swift_code_key"})
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO swift_codes")).
+		WillReturnError(&pgconn.PgError{Code: "23514", ConstraintName: "swift_codes_bic_country_check"})
+
+	err = repo.InsertSwiftCode(&models.SwiftCode{SwiftCode: "BANKUS33XXX"})
+	assert.ErrorIs(t, err, repositories.ErrDuplicateSwiftCode)
+
+	err = repo.InsertSwiftCode(&models.SwiftCode{SwiftCode: "BANKUS33XXX"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, repositories.ErrDuplicateSwiftCode, "Other constraint violations are not duplicates")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestInsertSwiftCode_ConflictInTx - a duplicate in the middle of a transaction is skipped, so the later inserts and the commit succeed
+func TestInsertSwiftCode_ConflictInTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+	query := regexp.QuoteMeta("INSERT INTO swift_codes") + ".*" + regexp.QuoteMeta("ON CONFLICT (swift_code) DO NOTHING RETURNING id;")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).WithArgs("BANKPLPWAAA", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(query).WithArgs("BANKPLPWXXX", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(query).WithArgs("BANKPLPWBBB", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectCommit()
+
+	var duplicates []string
+	err = repo.WithTx(context.Background(), func(tx repositories.SwiftCodeRepositoryInterface) error {
+		for _, code := range []string{"BANKPLPWAAA", "BANKPLPWXXX", "BANKPLPWBBB"} {
+			err := tx.InsertSwiftCode(&models.SwiftCode{SwiftCode: code})
+			if errors.Is(err, repositories.ErrDuplicateSwiftCode) {
+				duplicates = append(duplicates, code)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BANKPLPWXXX"}, duplicates)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateSwiftCode_VersionConflict - an update matching no row at the read version is reported as ErrVersionConflict
+func TestUpdateSwiftCode_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+	swift := &models.SwiftCode{SwiftCode: "BANKUS33XXX", BankName: "Bank A", CountryISO2: "US", CountryName: "UNITED STATES", IsHeadquarter: true, Version: 4}
+
+	query := regexp.QuoteMeta("UPDATE swift_codes SET bank_name = $2, address = $3, country_iso2 = $4, country_name = $5, is_headquarter = $6, headquarter_id = $7, code_type = $8, town_name = $9, time_zone = $10 WHERE swift_code = $1 AND version = $11;")
+	mock.ExpectExec(query).WithArgs("BANKUS33XXX", "Bank A", "", "US", "UNITED STATES", true, nil, "", "", "", int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("BANKUS33XXX", "Bank A", "", "US", "UNITED STATES", true, nil, "", "", "", int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UpdateSwiftCode(swift))
+	assert.ErrorIs(t, repo.UpdateSwiftCode(swift), repositories.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeleteSwiftCode_VersionConflict - a delete matching no row at the read version is reported as ErrVersionConflict
+func TestDeleteSwiftCode_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repositories.NewSwiftCodeRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM swift_codes WHERE swift_code = $1 AND version = $2;")).
+		WithArgs("BANKUS33XXX", int64(4)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeleteSwiftCode("BANKUS33XXX", 4)
+	assert.ErrorIs(t, err, repositories.ErrVersionConflict)
+	assert.EqualError(t, err, "SWIFT code was changed concurrently: BANKUS33XXX")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,54 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/mroczekDNF/swift-api/internal/models"
+	"github.com/mroczekDNF/swift-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffSwiftCodes tests that added, changed, removed and unchanged records are detected.
+func TestDiffSwiftCodes(t *testing.T) {
+	hqID := int64(1)
+	existing := []models.SwiftCode{
+		{ID: 1, SwiftCode: "ABCDPLPWXXX", BankName: "Bank HQ", Address: "Main Street 1", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{ID: 2, SwiftCode: "ABCDPLPW001", BankName: "Branch Bank", Address: "Branch Street 2", CountryISO2: "PL", CountryName: "POLAND", HeadquarterID: &hqID},
+		{ID: 3, SwiftCode: "EFGHPLPWXXX", BankName: "Closed Bank", Address: "Old Street 3", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+	}
+	parsed := []models.SwiftCode{
+		{SwiftCode: "ABCDPLPWXXX", BankName: "Bank HQ", Address: "Main Street 1", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "ABCDPLPW001", BankName: "Branch Bank", Address: "Branch Street 22", CountryISO2: "PL", CountryName: "POLAND"},
+		{SwiftCode: "ABCDPLPW002", BankName: "New Branch", Address: "New Street 4", CountryISO2: "PL", CountryName: "POLAND"},
+	}
+
+	plan := services.DiffSwiftCodes(existing, parsed)
+
+	assert.Len(t, plan.ToInsert, 1)
+	assert.Equal(t, "ABCDPLPW002", plan.ToInsert[0].SwiftCode)
+	assert.Len(t, plan.ToUpdate, 1)
+	assert.Equal(t, "ABCDPLPW001", plan.ToUpdate[0].SwiftCode)
+	assert.Equal(t, "Branch Street 22", plan.ToUpdate[0].Address)
+	assert.Equal(t, []string{"EFGHPLPWXXX"}, plan.ToRemove)
+	assert.Equal(t, 1, plan.Unchanged)
+
+	assert.Equal(t, services.SyncResult{Added: 1, Changed: 1, Removed: 1, Unchanged: 1}, plan.Result())
+}
+
+// TestDiffSwiftCodesIgnoresLinks tests that differing headquarter links alone do not count as a change.
+func TestDiffSwiftCodesIgnoresLinks(t *testing.T) {
+	hqID := int64(10)
+	existing := []models.SwiftCode{
+		{ID: 2, SwiftCode: "ABCDPLPW001", BankName: "Branch Bank", Address: "UNKNOWN", CountryISO2: "PL", CountryName: "POLAND", HeadquarterID: &hqID},
+	}
+	parsed := []models.SwiftCode{
+		{SwiftCode: "ABCDPLPW001", BankName: "Branch Bank", Address: "UNKNOWN", CountryISO2: "PL", CountryName: "POLAND"},
+	}
+
+	plan := services.DiffSwiftCodes(existing, parsed)
+
+	assert.Empty(t, plan.ToInsert)
+	assert.Empty(t, plan.ToUpdate)
+	assert.Empty(t, plan.ToRemove)
+	assert.Equal(t, 1, plan.Unchanged)
+}